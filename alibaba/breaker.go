@@ -0,0 +1,296 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"third_party_tool_library"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+const (
+	// DefaultCircuitFailureThreshold 默认连续失败多少次后熔断
+	DefaultCircuitFailureThreshold = 5
+	// DefaultCircuitCoolDown 默认的熔断时间，之后进入半开状态
+	DefaultCircuitCoolDown = 30 * time.Second
+	// DefaultCircuitHalfOpenCalls 半开状态默认同时允许的试探请求数
+	DefaultCircuitHalfOpenCalls = 1
+)
+
+// ErrCircuitOpen 短信接口已熔断，请求未发送
+var ErrCircuitOpen = errors.New("短信接口已熔断，请稍后重试")
+
+// CircuitState 熔断器状态
+type CircuitState int32
+
+const (
+	// CircuitClosed 关闭：请求正常发送
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 打开：请求直接返回 ErrCircuitOpen，不调用短信接口
+	CircuitOpen
+	// CircuitHalfOpen 半开：允许少量试探请求，成功后关闭，失败后重新打开
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int32(s))
+	}
+}
+
+// CircuitBreakerConfig 熔断器配置
+type CircuitBreakerConfig struct {
+	// 连续失败多少次后熔断，为 0 时使用 DefaultCircuitFailureThreshold
+	FailureThreshold int
+	// 熔断时间，之后进入半开状态，为 0 时使用 DefaultCircuitCoolDown
+	CoolDown time.Duration
+	// 半开状态同时允许的试探请求数，为 0 时使用 DefaultCircuitHalfOpenCalls
+	HalfOpenCalls int
+	// 状态变化时的回调，可用于告警或上报指标，在持有锁时调用，不能在回调中访问熔断器
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitStats 熔断器的状态和计数，用于健康检查和指标上报
+type CircuitStats struct {
+	// 当前状态
+	State CircuitState
+	// 连续失败的次数
+	ConsecutiveFailures int
+	// 最近一次熔断的时间，未熔断过时为零值
+	OpenedAt time.Time
+	// 累计成功的调用次数
+	Successes int64
+	// 累计失败的调用次数
+	Failures int64
+	// 累计因熔断而拒绝的请求数
+	Rejected int64
+}
+
+// CircuitBreaker 短信接口的熔断器，可以在多个客户端之间共享
+/**
+ * 短信接口故障时，每次调用都要等到超时才返回，熔断后请求直接返回 ErrCircuitOpen，避免调用方的协程堆积
+ * 网络错误、超时、服务端 5xx 错误和 isp.SYSTEM_ERROR 计为失败，号码不合法等业务错误说明接口可用，计为成功
+ */
+type CircuitBreaker struct {
+	failureThreshold int
+	coolDown         time.Duration
+	halfOpenCalls    int
+	onStateChange    func(from, to CircuitState)
+
+	mu    sync.Mutex
+	stats CircuitStats
+	// 半开状态正在进行的试探请求数
+	probes int
+	// 状态每变化一次加一，用于丢弃状态变化之前开始的请求结果
+	generation uint64
+}
+
+// NewCircuitBreaker
+/** 创建熔断器
+ * @param config 熔断器配置，零值使用默认配置（连续失败 5 次熔断 30 秒）
+ * @return *CircuitBreaker 熔断器，通过 WithCircuitBreaker 用于客户端
+ * @return error 配置小于 0 时返回错误
+ */
+func NewCircuitBreaker(config CircuitBreakerConfig) (*CircuitBreaker, error) {
+	if config.FailureThreshold < 0 || config.CoolDown < 0 || config.HalfOpenCalls < 0 {
+		return nil, errors.New("熔断器的失败次数、熔断时间和试探请求数不能小于 0")
+	}
+	b := &CircuitBreaker{
+		failureThreshold: config.FailureThreshold,
+		coolDown:         config.CoolDown,
+		halfOpenCalls:    config.HalfOpenCalls,
+		onStateChange:    config.OnStateChange,
+	}
+	if b.failureThreshold == 0 {
+		b.failureThreshold = DefaultCircuitFailureThreshold
+	}
+	if b.coolDown == 0 {
+		b.coolDown = DefaultCircuitCoolDown
+	}
+	if b.halfOpenCalls == 0 {
+		b.halfOpenCalls = DefaultCircuitHalfOpenCalls
+	}
+	return b, nil
+}
+
+// 创建配置相同、单独计数的熔断器
+func (b *CircuitBreaker) clone() *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: b.failureThreshold,
+		coolDown:         b.coolDown,
+		halfOpenCalls:    b.halfOpenCalls,
+		onStateChange:    b.onStateChange,
+	}
+}
+
+// State 当前状态，熔断时间已过的打开状态返回半开
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh(time.Now())
+	return b.stats.State
+}
+
+// Stats 当前状态和计数
+func (b *CircuitBreaker) Stats() CircuitStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh(time.Now())
+	return b.stats
+}
+
+// 熔断时间已过时进入半开状态，调用方需持有锁
+func (b *CircuitBreaker) refresh(now time.Time) {
+	if b.stats.State == CircuitOpen && now.Sub(b.stats.OpenedAt) >= b.coolDown {
+		b.setState(CircuitHalfOpen)
+	}
+}
+
+// 切换状态，调用方需持有锁
+func (b *CircuitBreaker) setState(state CircuitState) {
+	from := b.stats.State
+	if from == state {
+		return
+	}
+	b.stats.State = state
+	b.generation++
+	b.probes = 0
+	switch state {
+	case CircuitOpen:
+		b.stats.OpenedAt = time.Now()
+	case CircuitClosed:
+		b.stats.ConsecutiveFailures = 0
+	}
+	if b.onStateChange != nil {
+		b.onStateChange(from, state)
+	}
+}
+
+// 请求是否可以发送，可以发送时返回本次请求所在的状态代数
+func (b *CircuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh(time.Now())
+	switch b.stats.State {
+	case CircuitOpen:
+		b.stats.Rejected++
+		return 0, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.halfOpenCalls {
+			b.stats.Rejected++
+			return 0, ErrCircuitOpen
+		}
+		b.probes++
+	}
+	return b.generation, nil
+}
+
+// 记录请求结果，状态已经变化时只计数，不影响当前状态
+func (b *CircuitBreaker) record(generation uint64, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if failed {
+		b.stats.Failures++
+	} else {
+		b.stats.Successes++
+	}
+	if generation != b.generation {
+		return
+	}
+	switch b.stats.State {
+	case CircuitClosed:
+		if !failed {
+			b.stats.ConsecutiveFailures = 0
+			return
+		}
+		b.stats.ConsecutiveFailures++
+		if b.stats.ConsecutiveFailures >= b.failureThreshold {
+			b.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		if failed {
+			b.stats.ConsecutiveFailures++
+			b.setState(CircuitOpen)
+		} else {
+			b.setState(CircuitClosed)
+		}
+	}
+}
+
+// 归还被调用方取消的请求占用的试探名额
+func (b *CircuitBreaker) cancel(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation == b.generation && b.stats.State == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// 判断调用是否说明短信接口故障，调用方取消的请求不计入结果
+func circuitFailure(statusCode int32, result third_party_tool_library.ResponseResult, err error) (failed, ignored bool) {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return false, true
+		}
+		var sdkErr *tea.SDKError
+		if errors.As(err, &sdkErr) {
+			return tea.IntValue(sdkErr.StatusCode) >= 500, false
+		}
+		// 网络错误或超时
+		return true, false
+	}
+	return statusCode >= 500 || tea.StringValue(result.Code) == "isp.SYSTEM_ERROR", false
+}
+
+// 在熔断器的保护下发送，breaker 为 nil（未配置熔断器）时直接发送
+func withBreaker(breaker *CircuitBreaker, send func(opts []CallOption) (int32, third_party_tool_library.ResponseResult, error)) func(opts []CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	if breaker == nil {
+		return send
+	}
+	return func(opts []CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+		generation, err := breaker.allow()
+		if err != nil {
+			return 503, third_party_tool_library.ResponseResult{}, err
+		}
+		statusCode, result, err := send(opts)
+		if failed, ignored := circuitFailure(statusCode, result, err); ignored {
+			breaker.cancel(generation)
+		} else {
+			breaker.record(generation, failed)
+		}
+		return statusCode, result, err
+	}
+}
+
+// CircuitState
+/** 国内短信接口熔断器的当前状态，可用于健康检查
+ * @return CircuitState 未配置 WithCircuitBreaker 时总是 CircuitClosed
+ */
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.State()
+}
+
+// GlobeCircuitState
+/** 国际/港澳台短信接口熔断器的当前状态，可用于健康检查
+ * @return CircuitState 未配置 WithCircuitBreaker 和 WithGlobeCircuitBreaker 时总是 CircuitClosed
+ */
+func (c *Client) GlobeCircuitState() CircuitState {
+	if c.globeBreaker == nil {
+		return CircuitClosed
+	}
+	return c.globeBreaker.State()
+}
@@ -0,0 +1,84 @@
+package alibaba
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerPerEndpoint(t *testing.T) {
+	server := newSmsServer(t, func(url.Values) map[string]string {
+		return map[string]string{"Code": "isp.SYSTEM_ERROR", "Message": "系统错误"}
+	})
+	breaker, err := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, server, WithCircuitBreaker(breaker))
+
+	if _, _, err = client.Send(SendRequest{Recipients: Recipients("13800000000"), TemplateCode: "SMS_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if state := client.CircuitState(); state != CircuitOpen {
+		t.Fatalf("CircuitState() = %v, want open", state)
+	}
+	if statusCode, _, err := client.Send(SendRequest{Recipients: Recipients("13800000000"), TemplateCode: "SMS_1"}); statusCode != 503 || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Send() = %d, %v, want 503 ErrCircuitOpen", statusCode, err)
+	}
+	// 国内短信熔断不影响国际短信
+	if client.globeBreaker == breaker {
+		t.Fatal("globe sends share the domestic breaker")
+	}
+	if state := client.GlobeCircuitState(); state != CircuitClosed {
+		t.Fatalf("GlobeCircuitState() = %v, want closed", state)
+	}
+	if client.globeBreaker.failureThreshold != 1 || client.globeBreaker.coolDown != time.Minute {
+		t.Fatalf("globe breaker config = %d, %v", client.globeBreaker.failureThreshold, client.globeBreaker.coolDown)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	var transitions []CircuitState
+	breaker, err := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		CoolDown:         10 * time.Millisecond,
+		OnStateChange:    func(_, to CircuitState) { transitions = append(transitions, to) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		generation, err := breaker.allow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		breaker.record(generation, true)
+	}
+	if _, err = breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() while open = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	// 半开状态只允许一个试探请求
+	probe, err := breaker.allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second probe = %v, want ErrCircuitOpen", err)
+	}
+	breaker.record(probe, false)
+	stats := breaker.Stats()
+	if stats.State != CircuitClosed || stats.Rejected != 2 || stats.Failures != 2 || stats.Successes != 1 {
+		t.Fatalf("Stats() = %+v", stats)
+	}
+	want := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("transitions = %v, want %v", transitions, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package alibaba
+
+import (
+	"time"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+)
+
+// Client 短信客户端
+/**
+ * 长期持有的短信客户端，创建一次后可在多个 goroutine 中并发使用。
+ * 短信发送、签名管理、模板管理等操作都以方法的形式提供，避免每次调用都重新创建底层客户端并传递 AK&SK。
+ */
+type Client struct {
+	// 凭证，每次请求从中取一份固定的凭证用于签名
+	credential *providerCredential
+	sms        *dysmsapi20170525.Client
+	// 国际短信接口的客户端
+	globe *openapi.Client
+	// 每次调用的默认运行时参数
+	runtime util.RuntimeOptions
+	// 默认短信签名
+	signName string
+	// 命名的短信模板
+	templates map[string]Template
+	// 发送限流器，未配置时为 nil
+	limiter *tokenBucket
+	// 发送前是否校验模板参数
+	validateParams bool
+	// 模板变量缓存
+	templateCache *templateCache
+	// 国际短信默认的发送方 ID
+	senderId string
+	// 幂等记录存储，未配置时为 nil
+	dedup DedupStore
+	// 幂等键的保留时间
+	dedupTTL time.Duration
+	// 发送短信的重试策略，未配置时为 nil
+	retry *retryPolicy
+	// 本地限流器，未配置时为 nil
+	flow *FlowLimiter
+	// 国内短信接口的熔断器，未配置时为 nil
+	breaker *CircuitBreaker
+	// 国际/港澳台短信接口的熔断器，与国内短信分别计数，未配置时为 nil
+	globeBreaker *CircuitBreaker
+}
+
+// NewClient
+/** 创建短信客户端
+ * @param accessKeyId 访问密钥id，与 accessKeySecret 都为空时使用默认凭证链
+ * @param accessKeySecret 访问秘钥凭证
+ * @param opts 客户端配置项，例如 WithRegionId、WithEndpoint
+ * @return *Client 短信客户端
+ * @return error 错误响应对象
+ */
+func NewClient(accessKeyId, accessKeySecret string, opts ...Option) (*Client, error) {
+	return NewClientWithProvider(credentialProvider(accessKeyId, accessKeySecret), opts...)
+}
+
+// NewClientWithProvider
+/** 使用凭证提供者创建短信客户端
+ * @param provider 凭证提供者，例如 NewCredentialChain(DefaultCredentialProviders()...)
+ * @param opts 客户端配置项，例如 WithRegionId、WithEndpoint
+ * @return *Client 短信客户端
+ * @return error 错误响应对象
+ */
+func NewClientWithProvider(provider CredentialProvider, opts ...Option) (*Client, error) {
+	options, err := newClientOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	credential := newProviderCredential(provider)
+	sms, err := newSmsClient(credential, options)
+	if err != nil {
+		return nil, err
+	}
+	globe, err := newGlobeClient(sms.Credential, options)
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{
+		credential: credential,
+		sms:        sms,
+		globe:      globe,
+		runtime:    options.runtime(),
+		signName:   options.signName,
+		templates:  options.templates,
+
+		validateParams: options.validateParams,
+		templateCache:  newTemplateCache(options.templateCacheTTL),
+		senderId:       options.senderId,
+		dedup:          options.dedup,
+		dedupTTL:       options.dedupTTL,
+		retry:          options.retry,
+		flow:           options.flow,
+		breaker:        options.breaker,
+		globeBreaker:   options.globeBreaker,
+	}
+	// 两个服务地址分别熔断，避免一个接口故障时另一个接口的请求也被拒绝
+	if client.globeBreaker == nil && client.breaker != nil {
+		client.globeBreaker = client.breaker.clone()
+	}
+	if options.qps > 0 {
+		client.limiter = newTokenBucket(options.qps, options.burst)
+	}
+	return client, nil
+}
+
+// 生成本次调用的运行时参数
+func (c *Client) runtimeOptions(opts []CallOption) *util.RuntimeOptions {
+	runtime := c.runtime
+	for _, opt := range opts {
+		opt(&runtime)
+	}
+	return &runtime
+}
+
+// 本次请求使用的短信接口客户端：复制底层客户端并固定凭证，保证签名读取的 AK、Secret 和 Token 来自同一份凭证
+func (c *Client) smsClient() *dysmsapi20170525.Client {
+	sms := *c.sms
+	sms.Credential = c.credential.snapshot()
+	return &sms
+}
+
+// 本次请求使用的国际短信接口客户端，见 smsClient
+func (c *Client) globeClient() *openapi.Client {
+	globe := *c.globe
+	globe.Credential = c.credential.snapshot()
+	return &globe
+}
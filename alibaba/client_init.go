@@ -4,6 +4,7 @@ import (
 	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
 	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
 	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyun/credentials-go/credentials"
 )
 
 // CreateClient
@@ -12,21 +13,62 @@ import (
  * 使用AK&SK初始化账号Client
  * 工程代码泄露可能会导致 AccessKey 泄露，并威胁账号下所有资源的安全性。以下代码示例使用环境变量获取 AccessKey 的方式进行调用，仅供参考，
  * 建议使用更安全的 STS 方式，更多鉴权访问方式请参见：https://help.aliyun.com/document_detail/378661.html
+ * AK&SK 为空时将使用默认凭证链（DefaultCredentialProviders）获取凭证
  * @param accessKeyId 访问密钥id
  * @param accessKeySecret 访问秘钥凭证
  * @return Client 访问客户端
  * @throws Exception 返回异常信息
  */
 func CreateClient(accessKeyId *string, accessKeySecret *string) (client *dysmsapi20170525.Client, _err error) {
-	config := &openapi.Config{
-		// 必填，您的 AccessKey ID
-		AccessKeyId: accessKeyId,
-		// 必填，您的 AccessKey Secret
-		AccessKeySecret: accessKeySecret,
+	return CreateClientWithProvider(credentialProvider(tea.StringValue(accessKeyId), tea.StringValue(accessKeySecret)))
+}
+
+// CreateClientWithProvider
+/**
+ * 使用凭证提供者初始化账号Client，凭证在创建时解析一次，之后每次请求签名时从提供者读取（临时凭证会在过期前自动刷新）
+ * 返回的 SDK 客户端使用 v3 签名，每次请求只取一份凭证快照，AK、Secret 和 Token 来自同一份凭证，凭证刷新或轮换时不会混用新旧凭证；
+ * 不要把返回的客户端改为 v2 签名（SignatureAlgorithm 为 v2），v2 签名分别读取 AK、Secret 和 Token，凭证切换的瞬间发出的请求可能签名失败
+ * @param provider 凭证提供者，可以是单个提供者，也可以是 NewCredentialChain 创建的凭证链
+ * @param opts 客户端配置项，例如 WithRegionId、WithEndpoint
+ * @return Client 访问客户端
+ * @throws Exception 配置项不合法或没有可用的凭证时返回异常信息
+ */
+func CreateClientWithProvider(provider CredentialProvider, opts ...Option) (client *dysmsapi20170525.Client, _err error) {
+	options, _err := newClientOptions(opts)
+	if _err != nil {
+		return nil, _err
+	}
+	return newSmsClient(newProviderCredential(provider), options)
+}
+
+// 请求签名算法，与 SDK 未配置时的默认值相同
+const signatureAlgorithmV3 = "ACS3-HMAC-SHA256"
+
+func newSmsClient(credential *providerCredential, options *clientOptions) (*dysmsapi20170525.Client, error) {
+	if _, err := credential.resolve(); err != nil {
+		return nil, err
+	}
+	config := options.config()
+	// v3 签名每次请求只调用一次 GetCredential，取到的是 snapshot 固定下来的同一份凭证
+	config.SignatureAlgorithm = tea.String(signatureAlgorithmV3)
+	config.Credential = credential
+	return dysmsapi20170525.NewClient(config)
+}
+
+// 国际短信（2018-05-01 版本接口）使用的客户端，与国内短信共用凭证和连接配置
+func newGlobeClient(credential credentials.Credential, options *clientOptions) (*openapi.Client, error) {
+	config := options.config()
+	config.SignatureAlgorithm = tea.String(signatureAlgorithmV3)
+	config.Credential = credential
+	config.RegionId = tea.String(GlobeRegionId)
+	config.Endpoint = tea.String(firstNonEmpty(options.globeEndpoint, DefaultGlobeEndpoint))
+	return openapi.NewClient(config)
+}
+
+// 根据 AK&SK 选择凭证提供者，AK&SK 都为空时使用默认凭证链
+func credentialProvider(accessKeyId, accessKeySecret string) CredentialProvider {
+	if accessKeyId == "" && accessKeySecret == "" {
+		return NewCredentialChain(DefaultCredentialProviders()...)
 	}
-	// Endpoint 请参考 https://api.aliyun.com/product/Dysmsapi
-	config.Endpoint = tea.String("dysmsapi.aliyuncs.com")
-	client = &dysmsapi20170525.Client{}
-	client, _err = dysmsapi20170525.NewClient(config)
-	return client, _err
+	return NewStaticCredentialProvider(accessKeyId, accessKeySecret, "")
 }
@@ -0,0 +1,602 @@
+package alibaba
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvRegionId 环境变量：地域 ID
+	EnvRegionId = "ALIBABA_CLOUD_REGION_ID"
+	// EnvSmsEndpoint 环境变量：服务地址
+	EnvSmsEndpoint = "ALIBABA_CLOUD_SMS_ENDPOINT"
+	// EnvSmsProtocol 环境变量：请求协议
+	EnvSmsProtocol = "ALIBABA_CLOUD_SMS_PROTOCOL"
+	// EnvSmsNetwork 环境变量：网络类型
+	EnvSmsNetwork = "ALIBABA_CLOUD_SMS_NETWORK"
+	// EnvSmsConnectTimeout 环境变量：建立连接超时时间，例如 5s
+	EnvSmsConnectTimeout = "ALIBABA_CLOUD_SMS_CONNECT_TIMEOUT"
+	// EnvSmsReadTimeout 环境变量：请求超时时间，例如 10s
+	EnvSmsReadTimeout = "ALIBABA_CLOUD_SMS_READ_TIMEOUT"
+	// EnvSmsSignName 环境变量：默认短信签名
+	EnvSmsSignName = "ALIBABA_CLOUD_SMS_SIGN_NAME"
+	// EnvSmsTemplates 环境变量：命名的短信模板，格式为 名称=模板Code，多个以逗号分隔，例如 login=SMS_1,notify=SMS_2
+	EnvSmsTemplates = "ALIBABA_CLOUD_SMS_TEMPLATES"
+	// EnvSmsRetryMaxAttempts 环境变量：最多请求次数（包含第一次）
+	EnvSmsRetryMaxAttempts = "ALIBABA_CLOUD_SMS_RETRY_MAX_ATTEMPTS"
+	// EnvSmsRetryBackoff 环境变量：指数退避的基础时间，例如 1s
+	EnvSmsRetryBackoff = "ALIBABA_CLOUD_SMS_RETRY_BACKOFF"
+	// EnvSmsRetryPolicy 环境变量：是否按业务编码重试，true 或 false
+	EnvSmsRetryPolicy = "ALIBABA_CLOUD_SMS_RETRY_POLICY"
+	// EnvSmsRetryMaxDelay 环境变量：单次最长等待时间，例如 5s
+	EnvSmsRetryMaxDelay = "ALIBABA_CLOUD_SMS_RETRY_MAX_DELAY"
+	// EnvSmsRetryCodes 环境变量：额外可重试的业务编码，多个以逗号分隔
+	EnvSmsRetryCodes = "ALIBABA_CLOUD_SMS_RETRY_CODES"
+	// EnvSmsRateLimitQps 环境变量：每秒最多发送的次数
+	EnvSmsRateLimitQps = "ALIBABA_CLOUD_SMS_RATE_LIMIT_QPS"
+	// EnvSmsRateLimitBurst 环境变量：允许的突发次数
+	EnvSmsRateLimitBurst = "ALIBABA_CLOUD_SMS_RATE_LIMIT_BURST"
+	// EnvSmsCircuitBreaker 环境变量：是否开启熔断，true 或 false
+	EnvSmsCircuitBreaker = "ALIBABA_CLOUD_SMS_CIRCUIT_BREAKER"
+	// EnvSmsCircuitFailureThreshold 环境变量：连续失败多少次后熔断
+	EnvSmsCircuitFailureThreshold = "ALIBABA_CLOUD_SMS_CIRCUIT_FAILURE_THRESHOLD"
+	// EnvSmsCircuitCoolDown 环境变量：熔断时间，例如 30s
+	EnvSmsCircuitCoolDown = "ALIBABA_CLOUD_SMS_CIRCUIT_COOL_DOWN"
+)
+
+// Config 短信客户端配置
+/**
+ * 可以从 YAML、TOML、JSON 文件或环境变量中加载，用于创建完整配置的客户端，例如 YAML：
+ *	access_key_id: xxx
+ *	access_key_secret: xxx
+ *	region_id: cn-hangzhou
+ *	sign_name: 阿里云短信测试
+ *	templates:
+ *	  login:
+ *	    code: SMS_153055065
+ *	retry:
+ *	  policy: true
+ *	  max_attempts: 3
+ *	  backoff: 200ms
+ *	  max_delay: 5s
+ *	rate_limit:
+ *	  qps: 10
+ *	  burst: 20
+ *	flow_control:
+ *	  global:
+ *	    - {limit: 100, period: 1s}
+ *	  rules:
+ *	    verification:
+ *	      per_phone:
+ *	        - {limit: 1, period: 1m}
+ *	        - {limit: 10, period: 24h}
+ *	  template_types:
+ *	    SMS_153055065: verification
+ *	circuit_breaker:
+ *	  enabled: true
+ *	  failure_threshold: 5
+ *	  cool_down: 30s
+ * 时间类型的配置使用 Go 的时间格式，例如 500ms、5s、1m
+ */
+type Config struct {
+	// AccessKey ID，与 AccessKeySecret 都为空时使用 Profile 或默认凭证链
+	AccessKeyId string `json:"access_key_id" yaml:"access_key_id" toml:"access_key_id"`
+	// AccessKey Secret
+	AccessKeySecret string `json:"access_key_secret" yaml:"access_key_secret" toml:"access_key_secret"`
+	// STS 安全令牌，可为空
+	SecurityToken string `json:"security_token" yaml:"security_token" toml:"security_token"`
+	// 凭证配置文件路径，见 ProfileCredentialProvider
+	CredentialsFile string `json:"credentials_file" yaml:"credentials_file" toml:"credentials_file"`
+	// 凭证配置文件中使用的配置名称，见 ProfileCredentialProvider
+	Profile string `json:"profile" yaml:"profile" toml:"profile"`
+
+	// 服务地址，见 WithEndpoint
+	Endpoint string `json:"endpoint" yaml:"endpoint" toml:"endpoint"`
+	// 地域 ID，见 WithRegionId
+	RegionId string `json:"region_id" yaml:"region_id" toml:"region_id"`
+	// 请求协议，见 WithProtocol
+	Protocol string `json:"protocol" yaml:"protocol" toml:"protocol"`
+	// 网络类型，见 WithNetwork
+	Network string `json:"network" yaml:"network" toml:"network"`
+	// 国际/港澳台短信的服务地址，见 WithGlobeEndpoint
+	GlobeEndpoint string `json:"globe_endpoint" yaml:"globe_endpoint" toml:"globe_endpoint"`
+	// 建立连接超时时间
+	ConnectTimeout Duration `json:"connect_timeout" yaml:"connect_timeout" toml:"connect_timeout"`
+	// 请求超时时间
+	ReadTimeout Duration `json:"read_timeout" yaml:"read_timeout" toml:"read_timeout"`
+
+	// 默认短信签名
+	SignName string `json:"sign_name" yaml:"sign_name" toml:"sign_name"`
+	// 命名的短信模板，键为模板名称
+	Templates map[string]TemplateConfig `json:"templates" yaml:"templates" toml:"templates"`
+	// 国际/港澳台短信默认的发送方 ID，见 WithSenderId
+	SenderId string `json:"sender_id" yaml:"sender_id" toml:"sender_id"`
+	// 发送前是否校验模板参数，见 WithTemplateValidation
+	ValidateTemplateParams bool `json:"validate_template_params" yaml:"validate_template_params" toml:"validate_template_params"`
+	// 重试策略
+	Retry RetryConfig `json:"retry" yaml:"retry" toml:"retry"`
+	// 发送限流
+	RateLimit RateLimitConfig `json:"rate_limit" yaml:"rate_limit" toml:"rate_limit"`
+	// 本地限流规则，只能在配置文件中配置
+	FlowControl FlowControlConfig `json:"flow_control" yaml:"flow_control" toml:"flow_control"`
+	// 熔断
+	CircuitBreaker CircuitBreakerSettings `json:"circuit_breaker" yaml:"circuit_breaker" toml:"circuit_breaker"`
+}
+
+// TemplateConfig 命名的短信模板配置
+type TemplateConfig struct {
+	// 短信模板 Code
+	Code string `json:"code" yaml:"code" toml:"code"`
+	// 使用该模板时的短信签名，为空时使用默认签名
+	SignName string `json:"sign_name" yaml:"sign_name" toml:"sign_name"`
+}
+
+// RetryConfig 重试策略
+/**
+ * policy 为 true 时使用 WithRetryPolicy，按业务编码判断是否重试，未配置的项使用 RetryPolicy 的默认值；
+ * 否则使用 WithAutoRetry，由底层 SDK 重试网络错误和 5xx 错误，max_delay 和 retryable_codes 只在 policy 为 true 时有效
+ */
+type RetryConfig struct {
+	// 是否按业务编码重试，见 WithRetryPolicy
+	Policy bool `json:"policy" yaml:"policy" toml:"policy"`
+	// 最多请求次数（包含第一次），policy 为 false 时小于等于 1 表示不重试，policy 为 true 时为 0 使用 DefaultRetryMaxAttempts
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts" toml:"max_attempts"`
+	// 指数退避的基础时间
+	Backoff Duration `json:"backoff" yaml:"backoff" toml:"backoff"`
+	// 单次最长等待时间
+	MaxDelay Duration `json:"max_delay" yaml:"max_delay" toml:"max_delay"`
+	// 额外可重试的业务编码
+	RetryableCodes []string `json:"retryable_codes" yaml:"retryable_codes" toml:"retryable_codes"`
+}
+
+// 对应的重试策略
+func (c RetryConfig) policy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    c.MaxAttempts,
+		BaseDelay:      time.Duration(c.Backoff),
+		MaxDelay:       time.Duration(c.MaxDelay),
+		RetryableCodes: c.RetryableCodes,
+	}
+}
+
+// RateLimitConfig 发送限流，见 WithRateLimit
+type RateLimitConfig struct {
+	// 每秒最多发送的次数，为 0 表示不限制
+	Qps float64 `json:"qps" yaml:"qps" toml:"qps"`
+	// 允许的突发次数
+	Burst int `json:"burst" yaml:"burst" toml:"burst"`
+}
+
+// CircuitBreakerSettings 熔断配置，见 CircuitBreakerConfig 和 WithCircuitBreaker
+/**
+ * 每个由配置创建的客户端使用各自的熔断器，国内短信和国际/港澳台短信分别熔断；
+ * 需要多个客户端共同计数或设置 OnStateChange 时使用 NewCircuitBreaker 和 WithCircuitBreaker
+ */
+type CircuitBreakerSettings struct {
+	// 是否开启熔断
+	Enabled bool `json:"enabled" yaml:"enabled" toml:"enabled"`
+	// 连续失败多少次后熔断，为 0 时使用 DefaultCircuitFailureThreshold
+	FailureThreshold int `json:"failure_threshold" yaml:"failure_threshold" toml:"failure_threshold"`
+	// 熔断时间，为 0 时使用 DefaultCircuitCoolDown
+	CoolDown Duration `json:"cool_down" yaml:"cool_down" toml:"cool_down"`
+	// 半开状态同时允许的试探请求数，为 0 时使用 DefaultCircuitHalfOpenCalls
+	HalfOpenCalls int `json:"half_open_calls" yaml:"half_open_calls" toml:"half_open_calls"`
+}
+
+// 对应的熔断器
+func (c CircuitBreakerSettings) breaker() (*CircuitBreaker, error) {
+	return NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: c.FailureThreshold,
+		CoolDown:         time.Duration(c.CoolDown),
+		HalfOpenCalls:    c.HalfOpenCalls,
+	})
+}
+
+// FlowControlConfig 本地限流规则，见 FlowControl 和 WithFlowControl
+/**
+ * 每个由配置创建的客户端使用各自的限流器，需要多个客户端共同计数时使用 NewFlowLimiter 和 WithFlowControl
+ */
+type FlowControlConfig struct {
+	// 全局限流
+	Global []FlowLimitConfig `json:"global" yaml:"global" toml:"global"`
+	// 各模板类型的限流规则，键为模板类型：verification、notification、marketing、globe
+	Rules map[string]FlowRulesConfig `json:"rules" yaml:"rules" toml:"rules"`
+	// 未登记类型的模板使用的限流规则
+	Default FlowRulesConfig `json:"default" yaml:"default" toml:"default"`
+	// 模板 Code 对应的模板类型
+	TemplateTypes map[string]string `json:"template_types" yaml:"template_types" toml:"template_types"`
+}
+
+// FlowRulesConfig 一类短信模板的限流规则，见 FlowRules
+type FlowRulesConfig struct {
+	// 同一号码的限流
+	PerPhone []FlowLimitConfig `json:"per_phone" yaml:"per_phone" toml:"per_phone"`
+	// 同一模板的限流
+	PerTemplate []FlowLimitConfig `json:"per_template" yaml:"per_template" toml:"per_template"`
+}
+
+// FlowLimitConfig 限流规则：每 period 最多发送 limit 条，见 RateLimit
+type FlowLimitConfig struct {
+	// 周期内最多发送的条数
+	Limit int `json:"limit" yaml:"limit" toml:"limit"`
+	// 周期
+	Period Duration `json:"period" yaml:"period" toml:"period"`
+}
+
+// 是否配置了限流规则
+func (c FlowControlConfig) enabled() bool {
+	return len(c.Global) > 0 || len(c.Rules) > 0 || len(c.Default.PerPhone) > 0 || len(c.Default.PerTemplate) > 0
+}
+
+// 对应的限流器
+func (c FlowControlConfig) limiter() (*FlowLimiter, error) {
+	config := FlowControl{
+		Global:        rateLimits(c.Global),
+		Rules:         make(map[TemplateType]FlowRules, len(c.Rules)),
+		Default:       c.Default.rules(),
+		TemplateTypes: make(map[string]TemplateType, len(c.TemplateTypes)),
+	}
+	for name, rules := range c.Rules {
+		templateType, err := parseTemplateType(name)
+		if err != nil {
+			return nil, err
+		}
+		config.Rules[templateType] = rules.rules()
+	}
+	for templateCode, name := range c.TemplateTypes {
+		templateType, err := parseTemplateType(name)
+		if err != nil {
+			return nil, fmt.Errorf("模板 %s：%w", templateCode, err)
+		}
+		config.TemplateTypes[templateCode] = templateType
+	}
+	return NewFlowLimiter(config)
+}
+
+func (c FlowRulesConfig) rules() FlowRules {
+	return FlowRules{PerPhone: rateLimits(c.PerPhone), PerTemplate: rateLimits(c.PerTemplate)}
+}
+
+func rateLimits(limits []FlowLimitConfig) []RateLimit {
+	var result []RateLimit
+	for _, limit := range limits {
+		result = append(result, RateLimit{Limit: limit.Limit, Period: time.Duration(limit.Period)})
+	}
+	return result
+}
+
+// Duration 配置文件中的时间，使用 Go 的时间格式，例如 500ms、5s、1m
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(strings.TrimSpace(string(text)))
+	if err != nil {
+		return fmt.Errorf("时间 %s 不合法：%w", text, err)
+	}
+	*d = Duration(value)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// LoadConfig
+/** 从配置文件加载配置，根据扩展名识别格式：.yaml、.yml、.toml、.json
+ * 配置文件中出现未知的配置项时返回错误，避免拼写错误的配置被静默忽略
+ * 需要使用环境变量覆盖文件中的配置时，加载后调用 Config.LoadEnv
+ * @param filename 配置文件路径
+ * @return *Config 配置
+ * @return error 错误响应对象
+ */
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件 %s 失败：%w", filename, err)
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	config, err := ParseConfig(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败：%w", filename, err)
+	}
+	return config, nil
+}
+
+// ParseConfig
+/** 解析配置内容
+ * @param data 配置内容
+ * @param format 配置格式：yaml、yml、toml、json
+ * @return *Config 配置
+ * @return error 错误响应对象
+ */
+func ParseConfig(data []byte, format string) (*Config, error) {
+	config := &Config{}
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		// 空文件返回 io.EOF，视为空配置
+		if err := decoder.Decode(config); err != nil && len(bytes.TrimSpace(data)) > 0 {
+			return nil, err
+		}
+	case "toml":
+		meta, err := toml.Decode(string(data), config)
+		if err != nil {
+			return nil, err
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("未知的配置项：%s", undecoded[0])
+		}
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的配置格式 %s，仅支持 yaml、toml、json", format)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadConfigFromEnv
+/** 从环境变量加载配置，环境变量名称见 EnvAccessKeyId、EnvSmsEndpoint 等常量
+ * @return *Config 配置
+ * @return error 错误响应对象
+ */
+func LoadConfigFromEnv() (*Config, error) {
+	config := &Config{}
+	if err := config.LoadEnv(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadEnv
+/** 使用环境变量覆盖配置，只覆盖设置了的环境变量；模板按名称合并
+ * @return error 环境变量的值不合法时返回错误
+ */
+func (c *Config) LoadEnv() error {
+	setString := func(env string, target *string) {
+		if value, ok := os.LookupEnv(env); ok && value != "" {
+			*target = value
+		}
+	}
+	setString(EnvAccessKeyId, &c.AccessKeyId)
+	setString(EnvAccessKeySecret, &c.AccessKeySecret)
+	setString(EnvSecurityToken, &c.SecurityToken)
+	setString(EnvCredentialsFile, &c.CredentialsFile)
+	setString(EnvProfile, &c.Profile)
+	setString(EnvSmsEndpoint, &c.Endpoint)
+	setString(EnvRegionId, &c.RegionId)
+	setString(EnvSmsProtocol, &c.Protocol)
+	setString(EnvSmsNetwork, &c.Network)
+	setString(EnvSmsSignName, &c.SignName)
+
+	durations := []struct {
+		env    string
+		target *Duration
+	}{
+		{EnvSmsConnectTimeout, &c.ConnectTimeout},
+		{EnvSmsReadTimeout, &c.ReadTimeout},
+		{EnvSmsRetryBackoff, &c.Retry.Backoff},
+		{EnvSmsRetryMaxDelay, &c.Retry.MaxDelay},
+		{EnvSmsCircuitCoolDown, &c.CircuitBreaker.CoolDown},
+	}
+	for _, d := range durations {
+		if value := os.Getenv(d.env); value != "" {
+			if err := d.target.UnmarshalText([]byte(value)); err != nil {
+				return fmt.Errorf("环境变量 %s：%w", d.env, err)
+			}
+		}
+	}
+	ints := []struct {
+		env    string
+		target *int
+	}{
+		{EnvSmsRetryMaxAttempts, &c.Retry.MaxAttempts},
+		{EnvSmsRateLimitBurst, &c.RateLimit.Burst},
+		{EnvSmsCircuitFailureThreshold, &c.CircuitBreaker.FailureThreshold},
+	}
+	for _, i := range ints {
+		if value := os.Getenv(i.env); value != "" {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("环境变量 %s 必须是整数：%w", i.env, err)
+			}
+			*i.target = n
+		}
+	}
+	bools := []struct {
+		env    string
+		target *bool
+	}{
+		{EnvSmsRetryPolicy, &c.Retry.Policy},
+		{EnvSmsCircuitBreaker, &c.CircuitBreaker.Enabled},
+	}
+	for _, b := range bools {
+		if value := os.Getenv(b.env); value != "" {
+			enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("环境变量 %s 必须是 true 或 false：%w", b.env, err)
+			}
+			*b.target = enabled
+		}
+	}
+	if value := os.Getenv(EnvSmsRetryCodes); value != "" {
+		c.Retry.RetryableCodes = nil
+		for _, code := range strings.Split(value, ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				c.Retry.RetryableCodes = append(c.Retry.RetryableCodes, code)
+			}
+		}
+	}
+	if value := os.Getenv(EnvSmsRateLimitQps); value != "" {
+		qps, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("环境变量 %s 必须是数字：%w", EnvSmsRateLimitQps, err)
+		}
+		c.RateLimit.Qps = qps
+	}
+	if value := os.Getenv(EnvSmsTemplates); value != "" {
+		for _, item := range strings.Split(value, ",") {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			name, code, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("环境变量 %s 格式不合法：%s，应为 名称=模板Code", EnvSmsTemplates, item)
+			}
+			if c.Templates == nil {
+				c.Templates = make(map[string]TemplateConfig)
+			}
+			template := c.Templates[strings.TrimSpace(name)]
+			template.Code = strings.TrimSpace(code)
+			c.Templates[strings.TrimSpace(name)] = template
+		}
+	}
+	return c.Validate()
+}
+
+// Validate 校验配置，客户端相关的配置项（服务地址、地域等）在创建客户端时校验
+func (c *Config) Validate() error {
+	if (c.AccessKeyId == "") != (c.AccessKeySecret == "") {
+		return fmt.Errorf("access_key_id 与 access_key_secret 必须同时配置")
+	}
+	for name, template := range c.Templates {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("模板名称不能为空")
+		}
+		if strings.TrimSpace(template.Code) == "" {
+			return fmt.Errorf("模板 %s 的模板 Code 不能为空", name)
+		}
+	}
+	if c.Retry.MaxAttempts < 0 {
+		return fmt.Errorf("最多请求次数不能小于 0")
+	}
+	if c.Retry.Backoff < 0 {
+		return fmt.Errorf("退避时间不能小于 0")
+	}
+	if c.Retry.Policy {
+		if _, err := newRetryPolicy(c.Retry.policy()); err != nil {
+			return err
+		}
+	} else if c.Retry.MaxDelay != 0 || len(c.Retry.RetryableCodes) > 0 {
+		return fmt.Errorf("max_delay 和 retryable_codes 只在 retry.policy 为 true 时有效")
+	}
+	if c.RateLimit.Qps < 0 {
+		return fmt.Errorf("发送速率不能小于 0")
+	}
+	if c.FlowControl.enabled() {
+		if _, err := c.FlowControl.limiter(); err != nil {
+			return err
+		}
+	}
+	if _, err := c.CircuitBreaker.breaker(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CredentialProvider
+/** 配置对应的凭证提供者
+ * 配置了 AK&SK 时使用静态凭证，配置了 profile 或 credentials_file 时使用配置文件凭证，否则使用默认凭证链
+ */
+func (c *Config) CredentialProvider() CredentialProvider {
+	if c.AccessKeyId != "" {
+		return NewStaticCredentialProvider(c.AccessKeyId, c.AccessKeySecret, c.SecurityToken)
+	}
+	if c.Profile != "" || c.CredentialsFile != "" {
+		return &ProfileCredentialProvider{Filename: c.CredentialsFile, Profile: c.Profile}
+	}
+	return NewCredentialChain(DefaultCredentialProviders()...)
+}
+
+// Options 配置对应的客户端配置项
+func (c *Config) Options() []Option {
+	var opts []Option
+	if c.Endpoint != "" {
+		opts = append(opts, WithEndpoint(c.Endpoint))
+	}
+	if c.GlobeEndpoint != "" {
+		opts = append(opts, WithGlobeEndpoint(c.GlobeEndpoint))
+	}
+	if c.RegionId != "" {
+		opts = append(opts, WithRegionId(c.RegionId))
+	}
+	if c.Protocol != "" {
+		opts = append(opts, WithProtocol(c.Protocol))
+	}
+	if c.Network != "" {
+		opts = append(opts, WithNetwork(c.Network))
+	}
+	if c.ConnectTimeout != 0 {
+		opts = append(opts, WithConnectTimeout(time.Duration(c.ConnectTimeout)))
+	}
+	if c.ReadTimeout != 0 {
+		opts = append(opts, WithReadTimeout(time.Duration(c.ReadTimeout)))
+	}
+	if c.SignName != "" {
+		opts = append(opts, WithSignName(c.SignName))
+	}
+	if c.SenderId != "" {
+		opts = append(opts, WithSenderId(c.SenderId))
+	}
+	for name, template := range c.Templates {
+		opts = append(opts, WithTemplate(name, Template{Code: template.Code, SignName: template.SignName}))
+	}
+	if c.ValidateTemplateParams {
+		opts = append(opts, WithTemplateValidation(0))
+	}
+	if c.Retry.Policy {
+		opts = append(opts, WithRetryPolicy(c.Retry.policy()))
+	} else if c.Retry.MaxAttempts > 1 {
+		opts = append(opts, WithAutoRetry(c.Retry.MaxAttempts, time.Duration(c.Retry.Backoff)))
+	}
+	if c.RateLimit.Qps > 0 {
+		opts = append(opts, WithRateLimit(c.RateLimit.Qps, c.RateLimit.Burst))
+	}
+	if c.FlowControl.enabled() {
+		limiter, err := c.FlowControl.limiter()
+		if err != nil {
+			opts = append(opts, func(*clientOptions) error { return err })
+		} else {
+			opts = append(opts, WithFlowControl(limiter))
+		}
+	}
+	if c.CircuitBreaker.Enabled {
+		breaker, err := c.CircuitBreaker.breaker()
+		if err != nil {
+			opts = append(opts, func(*clientOptions) error { return err })
+		} else {
+			opts = append(opts, WithCircuitBreaker(breaker))
+		}
+	}
+	return opts
+}
+
+// NewClientFromConfig
+/** 根据配置创建短信客户端
+ * @param config 配置，例如 LoadConfig 的返回值
+ * @param opts 额外的客户端配置项，覆盖配置中的对应配置
+ * @return *Client 短信客户端
+ * @return error 错误响应对象
+ */
+func NewClientFromConfig(config *Config, opts ...Option) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return NewClientWithProvider(config.CredentialProvider(), append(config.Options(), opts...)...)
+}
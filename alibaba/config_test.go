@@ -0,0 +1,305 @@
+package alibaba
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 三种格式的同一份基础配置
+var baseConfigFiles = map[string]string{
+	"sms.yaml": `
+access_key_id: id
+access_key_secret: secret
+region_id: cn-shanghai
+endpoint: dysmsapi.aliyuncs.com
+read_timeout: 3s
+sign_name: 阿里云短信测试
+templates:
+  login:
+    code: SMS_1
+  notice:
+    code: SMS_2
+    sign_name: 通知签名
+retry:
+  max_attempts: 3
+  backoff: 200ms
+`,
+	"sms.toml": `
+access_key_id = "id"
+access_key_secret = "secret"
+region_id = "cn-shanghai"
+endpoint = "dysmsapi.aliyuncs.com"
+read_timeout = "3s"
+sign_name = "阿里云短信测试"
+
+[templates.login]
+code = "SMS_1"
+
+[templates.notice]
+code = "SMS_2"
+sign_name = "通知签名"
+
+[retry]
+max_attempts = 3
+backoff = "200ms"
+`,
+	"sms.json": `{
+  "access_key_id": "id",
+  "access_key_secret": "secret",
+  "region_id": "cn-shanghai",
+  "endpoint": "dysmsapi.aliyuncs.com",
+  "read_timeout": "3s",
+  "sign_name": "阿里云短信测试",
+  "templates": {
+    "login": {"code": "SMS_1"},
+    "notice": {"code": "SMS_2", "sign_name": "通知签名"}
+  },
+  "retry": {"max_attempts": 3, "backoff": "200ms"}
+}`,
+}
+
+func writeConfigFile(t *testing.T, name, data string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	want := &Config{
+		AccessKeyId:     "id",
+		AccessKeySecret: "secret",
+		RegionId:        "cn-shanghai",
+		Endpoint:        "dysmsapi.aliyuncs.com",
+		ReadTimeout:     Duration(3 * time.Second),
+		SignName:        "阿里云短信测试",
+		Templates: map[string]TemplateConfig{
+			"login":  {Code: "SMS_1"},
+			"notice": {Code: "SMS_2", SignName: "通知签名"},
+		},
+		Retry: RetryConfig{MaxAttempts: 3, Backoff: Duration(200 * time.Millisecond)},
+	}
+	for name, data := range baseConfigFiles {
+		config, err := LoadConfig(writeConfigFile(t, name, data))
+		if err != nil {
+			t.Errorf("LoadConfig(%s) error = %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(config, want) {
+			t.Errorf("LoadConfig(%s) = %+v, want %+v", name, config, want)
+		}
+		client, err := NewClientFromConfig(config)
+		if err != nil {
+			t.Errorf("NewClientFromConfig(%s) error = %v", name, err)
+			continue
+		}
+		if client.signName != "阿里云短信测试" || client.templates["notice"] != (Template{Code: "SMS_2", SignName: "通知签名"}) {
+			t.Errorf("client from %s = sign %s, templates %v", name, client.signName, client.templates)
+		}
+	}
+
+	if _, err := LoadConfig(writeConfigFile(t, "sms.ini", "access_key_id = id")); err == nil {
+		t.Error("unsupported format was accepted")
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("missing file was accepted")
+	}
+	if config, err := ParseConfig([]byte("  \n"), "yaml"); err != nil || !reflect.DeepEqual(config, &Config{}) {
+		t.Errorf("ParseConfig(empty yaml) = %+v, %v", config, err)
+	}
+}
+
+func TestLoadConfigRejectsUnknownFields(t *testing.T) {
+	files := map[string]string{
+		"sms.yaml":        "access_key: id\n",
+		"nested.yaml":     "retry:\n  attempts: 3\n",
+		"sms.toml":        "access_key = \"id\"\n",
+		"nested.toml":     "[templates.login]\ncode = \"SMS_1\"\nsign = \"签名\"\n",
+		"sms.json":        `{"access_key": "id"}`,
+		"nested.json":     `{"rate_limit": {"rps": 10}}`,
+		"half_creds.yaml": "access_key_id: id\n",
+		"no_code.yaml":    "templates:\n  login:\n    sign_name: 签名\n",
+	}
+	for name, data := range files {
+		if _, err := LoadConfig(writeConfigFile(t, name, data)); err == nil {
+			t.Errorf("LoadConfig(%s) succeeded", name)
+		}
+	}
+}
+
+func TestConfigLoadEnv(t *testing.T) {
+	config, err := LoadConfig(writeConfigFile(t, "sms.yaml", baseConfigFiles["sms.yaml"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvAccessKeyId, "env-id")
+	t.Setenv(EnvAccessKeySecret, "env-secret")
+	t.Setenv(EnvSecurityToken, "env-token")
+	t.Setenv(EnvSmsReadTimeout, "500ms")
+	// 模板按名称合并：覆盖已有模板的 Code 并保留签名，新增未配置的模板
+	t.Setenv(EnvSmsTemplates, "notice=SMS_20, register = SMS_3,")
+	if err := config.LoadEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if config.AccessKeyId != "env-id" || config.AccessKeySecret != "env-secret" || config.SecurityToken != "env-token" {
+		t.Fatalf("credentials = %s %s %s", config.AccessKeyId, config.AccessKeySecret, config.SecurityToken)
+	}
+	if config.ReadTimeout != Duration(500*time.Millisecond) || config.RegionId != "cn-shanghai" {
+		t.Fatalf("config = %+v", config)
+	}
+	wantTemplates := map[string]TemplateConfig{
+		"login":    {Code: "SMS_1"},
+		"notice":   {Code: "SMS_20", SignName: "通知签名"},
+		"register": {Code: "SMS_3"},
+	}
+	if !reflect.DeepEqual(config.Templates, wantTemplates) {
+		t.Fatalf("templates = %v", config.Templates)
+	}
+	credential, err := config.CredentialProvider().Retrieve()
+	if err != nil || credential.AccessKeyId != "env-id" || credential.SecurityToken != "env-token" {
+		t.Fatalf("credential = %+v, %v", credential, err)
+	}
+}
+
+func TestLoadConfigFromEnvInvalid(t *testing.T) {
+	tests := []struct {
+		env   string
+		value string
+	}{
+		{EnvSmsTemplates, "login"},
+		{EnvSmsTemplates, "login="},
+		{EnvSmsConnectTimeout, "5"},
+		{EnvSmsRetryMaxAttempts, "three"},
+		{EnvSmsRateLimitQps, "fast"},
+		{EnvSmsCircuitBreaker, "sometimes"},
+		{EnvAccessKeyId, "id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(EnvAccessKeyId, "")
+			t.Setenv(EnvAccessKeySecret, "")
+			t.Setenv(tt.env, tt.value)
+			if _, err := LoadConfigFromEnv(); err == nil {
+				t.Errorf("%s=%q was accepted", tt.env, tt.value)
+			}
+		})
+	}
+}
+
+func TestConfigRetryPolicy(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+retry:
+  policy: true
+  max_attempts: 4
+  backoff: 100ms
+  max_delay: 1s
+  retryable_codes: [isv.BUSINESS_LIMIT_CONTROL]
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient("id", "secret", config.Options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.retry == nil || client.retry.maxAttempts != 4 || client.retry.baseDelay != 100*time.Millisecond ||
+		client.retry.maxDelay != time.Second || !client.retry.codes["isv.BUSINESS_LIMIT_CONTROL"] {
+		t.Fatalf("retry policy = %+v", client.retry)
+	}
+
+	invalid := []string{
+		"retry:\n  policy: true\n  retryable_codes: [isv.MOBILE_NUMBER_ILLEGAL]\n",
+		"retry:\n  policy: true\n  backoff: 2s\n  max_delay: 1s\n",
+		"retry:\n  max_delay: 1s\n",
+	}
+	for _, data := range invalid {
+		if _, err := ParseConfig([]byte(data), "yaml"); err == nil {
+			t.Errorf("ParseConfig(%q) succeeded", data)
+		}
+	}
+}
+
+func TestConfigRetryPolicyFromEnv(t *testing.T) {
+	t.Setenv(EnvSmsRetryPolicy, "true")
+	t.Setenv(EnvSmsRetryMaxDelay, "2s")
+	t.Setenv(EnvSmsRetryCodes, "isv.BUSINESS_LIMIT_CONTROL, isv.DAY_LIMIT_CONTROL")
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.Retry.Policy || config.Retry.MaxDelay != Duration(2*time.Second) || len(config.Retry.RetryableCodes) != 2 ||
+		config.Retry.RetryableCodes[1] != "isv.DAY_LIMIT_CONTROL" {
+		t.Fatalf("retry config = %+v", config.Retry)
+	}
+}
+
+func TestConfigFlowControl(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+[[flow_control.global]]
+limit = 100
+period = "1s"
+
+[flow_control.rules.verification]
+per_phone = [{limit = 1, period = "1m"}]
+
+[flow_control.template_types]
+SMS_1 = "verification"
+`), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient("id", "secret", config.Options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.flow == nil {
+		t.Fatal("flow control was not configured")
+	}
+	if err = client.flow.Allow("SMS_1", "13800000000"); err != nil {
+		t.Fatal(err)
+	}
+	var limited *RateLimitError
+	if err = client.flow.Allow("SMS_1", "13800000000"); !errors.As(err, &limited) || limited.Scope != FlowScopePhone {
+		t.Fatalf("second Allow() = %v, want phone limit", err)
+	}
+
+	invalid := []string{
+		"flow_control:\n  rules:\n    otp:\n      per_phone: [{limit: 1, period: 1m}]\n",
+		"flow_control:\n  global: [{limit: 0, period: 1s}]\n",
+		"flow_control:\n  global: [{limit: 1, period: 1s}]\n  template_types: {SMS_1: otp}\n",
+	}
+	for _, data := range invalid {
+		if _, err := ParseConfig([]byte(data), "yaml"); err == nil {
+			t.Errorf("ParseConfig(%q) succeeded", data)
+		}
+	}
+}
+
+func TestConfigCircuitBreaker(t *testing.T) {
+	t.Setenv(EnvSmsCircuitBreaker, "true")
+	t.Setenv(EnvSmsCircuitCoolDown, "10s")
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient("id", "secret", config.Options()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.breaker == nil || client.globeBreaker == nil || client.breaker == client.globeBreaker {
+		t.Fatalf("breakers = %p, %p, want two breakers", client.breaker, client.globeBreaker)
+	}
+	if client.breaker.coolDown != 10*time.Second || client.breaker.failureThreshold != DefaultCircuitFailureThreshold {
+		t.Fatalf("breaker config = %d, %v", client.breaker.failureThreshold, client.breaker.coolDown)
+	}
+
+	if _, err = ParseConfig([]byte("circuit_breaker:\n  enabled: true\n  failure_threshold: -1\n"), "yaml"); err == nil {
+		t.Fatal("ParseConfig() with negative failure_threshold succeeded")
+	}
+}
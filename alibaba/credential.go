@@ -0,0 +1,312 @@
+package alibaba
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	credentials "github.com/aliyun/credentials-go/credentials"
+)
+
+const (
+	// EnvAccessKeyId 环境变量：AccessKey ID
+	EnvAccessKeyId = "ALIBABA_CLOUD_ACCESS_KEY_ID"
+	// EnvAccessKeySecret 环境变量：AccessKey Secret
+	EnvAccessKeySecret = "ALIBABA_CLOUD_ACCESS_KEY_SECRET"
+	// EnvSecurityToken 环境变量：STS 安全令牌
+	EnvSecurityToken = "ALIBABA_CLOUD_SECURITY_TOKEN"
+)
+
+// ErrCredentialNotFound 凭证提供者没有找到可用的凭证
+var ErrCredentialNotFound = errors.New("未找到可用的访问凭证")
+
+// Credential 访问凭证
+type Credential struct {
+	// AccessKey ID
+	AccessKeyId string
+	// AccessKey Secret
+	AccessKeySecret string
+	// STS 安全令牌，使用长期 AccessKey 时为空
+	SecurityToken string
+	// 凭证过期时间，零值表示长期有效
+	Expiration time.Time
+}
+
+// 凭证在 window 时间内是否会过期
+func (c *Credential) expiresWithin(window time.Duration) bool {
+	if c.Expiration.IsZero() {
+		return false
+	}
+	return time.Now().Add(window).After(c.Expiration)
+}
+
+// CredentialProvider 凭证提供者
+/**
+ * 每个提供者负责从一种来源（环境变量、配置文件、实例元数据等）获取访问凭证
+ * 没有找到凭证时应返回 ErrCredentialNotFound（可包装），以便凭证链继续尝试下一个提供者
+ */
+type CredentialProvider interface {
+	Retrieve() (*Credential, error)
+}
+
+// StaticCredentialProvider 静态凭证提供者
+type StaticCredentialProvider struct {
+	credential Credential
+}
+
+// NewStaticCredentialProvider
+/** 使用固定的 AK&SK 创建凭证提供者
+ * @param accessKeyId 访问密钥id
+ * @param accessKeySecret 访问秘钥凭证
+ * @param securityToken STS 安全令牌，可为空
+ */
+func NewStaticCredentialProvider(accessKeyId, accessKeySecret, securityToken string) *StaticCredentialProvider {
+	return &StaticCredentialProvider{credential: Credential{
+		AccessKeyId:     accessKeyId,
+		AccessKeySecret: accessKeySecret,
+		SecurityToken:   securityToken,
+	}}
+}
+
+func (p *StaticCredentialProvider) Retrieve() (*Credential, error) {
+	if p.credential.AccessKeyId == "" || p.credential.AccessKeySecret == "" {
+		return nil, ErrCredentialNotFound
+	}
+	credential := p.credential
+	return &credential, nil
+}
+
+// EnvCredentialProvider 环境变量凭证提供者
+/**
+ * 读取 ALIBABA_CLOUD_ACCESS_KEY_ID、ALIBABA_CLOUD_ACCESS_KEY_SECRET 以及可选的 ALIBABA_CLOUD_SECURITY_TOKEN
+ */
+type EnvCredentialProvider struct{}
+
+func (p *EnvCredentialProvider) Retrieve() (*Credential, error) {
+	accessKeyId := strings.TrimSpace(os.Getenv(EnvAccessKeyId))
+	accessKeySecret := strings.TrimSpace(os.Getenv(EnvAccessKeySecret))
+	if accessKeyId == "" || accessKeySecret == "" {
+		return nil, ErrCredentialNotFound
+	}
+	return &Credential{
+		AccessKeyId:     accessKeyId,
+		AccessKeySecret: accessKeySecret,
+		SecurityToken:   strings.TrimSpace(os.Getenv(EnvSecurityToken)),
+	}, nil
+}
+
+// CredentialChain 凭证提供者链
+/**
+ * 按顺序尝试每一个提供者，第一个成功返回凭证的提供者会被记住，之后（包括凭证过期后的刷新）都只使用该提供者
+ */
+type CredentialChain struct {
+	providers []CredentialProvider
+	mu        sync.Mutex
+	resolved  CredentialProvider
+}
+
+// NewCredentialChain
+/** 创建凭证提供者链
+ * @param providers 按优先级排列的凭证提供者
+ */
+func NewCredentialChain(providers ...CredentialProvider) *CredentialChain {
+	return &CredentialChain{providers: providers}
+}
+
+// DefaultCredentialProviders
+/** 默认的凭证提供者顺序
+ * 环境变量 -> ~/.alibabacloud/credentials 配置文件 -> ECS 实例 RAM 角色 -> OIDC 角色 ARN
+ * ECS 实例 RAM 角色仅在设置了 ALIBABA_CLOUD_ECS_METADATA 环境变量时启用，避免在非 ECS 环境中等待元数据服务超时
+ */
+func DefaultCredentialProviders() []CredentialProvider {
+	providers := []CredentialProvider{&EnvCredentialProvider{}, &ProfileCredentialProvider{}}
+	if os.Getenv(EnvEcsMetadata) != "" {
+		providers = append(providers, &EcsRamRoleCredentialProvider{})
+	}
+	return append(providers, &OIDCRoleArnCredentialProvider{})
+}
+
+func (c *CredentialChain) Retrieve() (*Credential, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resolved != nil {
+		return c.resolved.Retrieve()
+	}
+	var errs []string
+	for _, provider := range c.providers {
+		credential, err := provider.Retrieve()
+		if err == nil {
+			c.resolved = provider
+			return credential, nil
+		}
+		if !errors.Is(err, ErrCredentialNotFound) {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New("凭证链中没有可用的凭证：" + strings.Join(errs, "；"))
+	}
+	return nil, ErrCredentialNotFound
+}
+
+const (
+	// 临时凭证在过期前多久开始后台刷新
+	credentialRefreshWindow = 5 * time.Minute
+	// 临时凭证在过期前多久视为不可用，必须同步刷新后才能继续使用
+	credentialExpiryWindow = 30 * time.Second
+	// 后台刷新失败后，至少等待多久再重试
+	credentialRefreshRetryInterval = 10 * time.Second
+)
+
+// providerCredential 将 CredentialProvider 适配为 SDK 使用的 credentials.Credential
+// 临时凭证进入刷新窗口后在后台重新获取，刷新期间继续使用旧凭证；只有凭证已经（或即将）过期时才会同步等待刷新。
+// SDK 签名时会分别调用 GetAccessKeyId、GetAccessKeySecret、GetSecurityToken，凭证恰好在几次调用之间切换时签名会失败，
+// 因此 Client 每次请求都通过 snapshot 取一份固定的凭证用于签名，直接使用 SDK 客户端时没有这一保证
+type providerCredential struct {
+	provider    CredentialProvider
+	mu          sync.Mutex
+	current     *Credential
+	refreshing  bool
+	nextRefresh time.Time
+}
+
+func newProviderCredential(provider CredentialProvider) *providerCredential {
+	return &providerCredential{provider: provider}
+}
+
+// liveCredentialProvider 自行维护当前凭证的提供者（例如 RotatingCredentialProvider），Retrieve 开销很小，
+// 适配器每次签名都直接读取，以便凭证轮换后立即生效
+type liveCredentialProvider interface {
+	CredentialProvider
+	live()
+}
+
+func (p *providerCredential) resolve() (*Credential, error) {
+	if live, ok := p.provider.(liveCredentialProvider); ok {
+		return live.Retrieve()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current == nil || p.current.expiresWithin(credentialExpiryWindow) {
+		credential, err := p.provider.Retrieve()
+		if err != nil {
+			return nil, err
+		}
+		p.current = credential
+		return credential, nil
+	}
+	if p.current.expiresWithin(credentialRefreshWindow) && !p.refreshing && time.Now().After(p.nextRefresh) {
+		p.refreshing = true
+		go p.refresh()
+	}
+	return p.current, nil
+}
+
+// 后台刷新临时凭证，失败时保留旧凭证并在稍后重试
+func (p *providerCredential) refresh() {
+	credential, err := p.provider.Retrieve()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refreshing = false
+	if err != nil {
+		p.nextRefresh = time.Now().Add(credentialRefreshRetryInterval)
+		return
+	}
+	p.current = credential
+}
+
+// snapshot 取出当前凭证，用于一次请求的签名
+func (p *providerCredential) snapshot() credentials.Credential {
+	credential, err := p.resolve()
+	return &credentialSnapshot{credential: credential, err: err}
+}
+
+func (p *providerCredential) GetAccessKeyId() (*string, error) {
+	credential, err := p.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return tea.String(credential.AccessKeyId), nil
+}
+
+func (p *providerCredential) GetAccessKeySecret() (*string, error) {
+	credential, err := p.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return tea.String(credential.AccessKeySecret), nil
+}
+
+func (p *providerCredential) GetSecurityToken() (*string, error) {
+	credential, err := p.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return tea.String(credential.SecurityToken), nil
+}
+
+func (p *providerCredential) GetBearerToken() *string {
+	return tea.String("")
+}
+
+func (p *providerCredential) GetType() *string {
+	return p.snapshot().GetType()
+}
+
+func (p *providerCredential) GetCredential() (*credentials.CredentialModel, error) {
+	return p.snapshot().GetCredential()
+}
+
+// credentialSnapshot 固定不变的凭证，一次请求签名读取的 AK、Secret 和 Token 都来自同一份凭证
+type credentialSnapshot struct {
+	credential *Credential
+	// 获取凭证失败的原因，签名时返回
+	err error
+}
+
+func (s *credentialSnapshot) GetAccessKeyId() (*string, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return tea.String(s.credential.AccessKeyId), nil
+}
+
+func (s *credentialSnapshot) GetAccessKeySecret() (*string, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return tea.String(s.credential.AccessKeySecret), nil
+}
+
+func (s *credentialSnapshot) GetSecurityToken() (*string, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return tea.String(s.credential.SecurityToken), nil
+}
+
+func (s *credentialSnapshot) GetBearerToken() *string {
+	return tea.String("")
+}
+
+func (s *credentialSnapshot) GetType() *string {
+	if s.err != nil || s.credential.SecurityToken == "" {
+		return tea.String("access_key")
+	}
+	return tea.String("sts")
+}
+
+func (s *credentialSnapshot) GetCredential() (*credentials.CredentialModel, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &credentials.CredentialModel{
+		AccessKeyId:     tea.String(s.credential.AccessKeyId),
+		AccessKeySecret: tea.String(s.credential.AccessKeySecret),
+		SecurityToken:   tea.String(s.credential.SecurityToken),
+		Type:            s.GetType(),
+	}, nil
+}
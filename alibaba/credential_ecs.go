@@ -0,0 +1,106 @@
+package alibaba
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvEcsMetadata 环境变量：ECS 实例绑定的 RAM 角色名称
+	EnvEcsMetadata = "ALIBABA_CLOUD_ECS_METADATA"
+	// DefaultEcsMetadataEndpoint ECS 实例元数据服务地址
+	DefaultEcsMetadataEndpoint = "http://100.100.100.200"
+
+	ecsSecurityCredentialsPath = "/latest/meta-data/ram/security-credentials/"
+)
+
+// 访问元数据服务和 STS 时默认使用的 http 客户端
+var credentialHTTPClient = &http.Client{Timeout: 5 * time.Second}
+
+// EcsRamRoleCredentialProvider ECS 实例 RAM 角色凭证提供者
+/**
+ * 从 ECS 实例元数据服务获取实例 RAM 角色的临时凭证，凭证过期前会自动重新获取
+ */
+type EcsRamRoleCredentialProvider struct {
+	// RAM 角色名称，为空时依次使用 ALIBABA_CLOUD_ECS_METADATA 环境变量和元数据服务返回的角色
+	RoleName string
+	// 元数据服务地址，为空时使用 DefaultEcsMetadataEndpoint，测试时可指向本地的模拟服务
+	MetadataEndpoint string
+	// 访问元数据服务使用的 http 客户端，为空时使用默认客户端
+	HTTPClient *http.Client
+}
+
+type ecsCredentialResponse struct {
+	Code            string
+	AccessKeyId     string
+	AccessKeySecret string
+	SecurityToken   string
+	Expiration      string
+}
+
+func (p *EcsRamRoleCredentialProvider) Retrieve() (*Credential, error) {
+	roleName := p.RoleName
+	if roleName == "" {
+		roleName = os.Getenv(EnvEcsMetadata)
+	}
+	if roleName == "" {
+		body, err := p.get(ecsSecurityCredentialsPath)
+		if err != nil {
+			return nil, fmt.Errorf("获取 ECS 实例 RAM 角色名称失败：%w", err)
+		}
+		roleName = strings.TrimSpace(strings.SplitN(string(body), "\n", 2)[0])
+		if roleName == "" {
+			return nil, fmt.Errorf("ECS 实例没有绑定 RAM 角色：%w", ErrCredentialNotFound)
+		}
+	}
+	body, err := p.get(ecsSecurityCredentialsPath + roleName)
+	if err != nil {
+		return nil, fmt.Errorf("获取 ECS 实例 RAM 角色 %s 的凭证失败：%w", roleName, err)
+	}
+	var resp ecsCredentialResponse
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析 ECS 实例 RAM 角色凭证失败：%w", err)
+	}
+	if resp.Code != "Success" {
+		return nil, fmt.Errorf("获取 ECS 实例 RAM 角色凭证失败：%s", resp.Code)
+	}
+	expiration, err := time.Parse(time.RFC3339, resp.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("解析 ECS 实例 RAM 角色凭证过期时间失败：%w", err)
+	}
+	return &Credential{
+		AccessKeyId:     resp.AccessKeyId,
+		AccessKeySecret: resp.AccessKeySecret,
+		SecurityToken:   resp.SecurityToken,
+		Expiration:      expiration,
+	}, nil
+}
+
+func (p *EcsRamRoleCredentialProvider) get(path string) ([]byte, error) {
+	endpoint := p.MetadataEndpoint
+	if endpoint == "" {
+		endpoint = DefaultEcsMetadataEndpoint
+	}
+	client := p.HTTPClient
+	if client == nil {
+		client = credentialHTTPClient
+	}
+	resp, err := client.Get(strings.TrimRight(endpoint, "/") + path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("元数据服务返回 %d：%s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
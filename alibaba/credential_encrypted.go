@@ -0,0 +1,295 @@
+package alibaba
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const (
+	// EnvCredentialsKey 环境变量：加密凭证文件的 AES-256 密钥（base64 或 hex 编码）
+	EnvCredentialsKey = "ALIBABA_CLOUD_CREDENTIALS_KEY"
+	// EnvCredentialsKeyFile 环境变量：加密凭证文件的 AES-256 密钥文件路径
+	EnvCredentialsKeyFile = "ALIBABA_CLOUD_CREDENTIALS_KEY_FILE"
+	// EnvCredentialsAgeIdentityFile 环境变量：解密 age 格式凭证文件的身份文件路径（age-keygen 生成）
+	EnvCredentialsAgeIdentityFile = "ALIBABA_CLOUD_CREDENTIALS_AGE_IDENTITY_FILE"
+)
+
+// AES-GCM 加密文件的首行，同时作为附加认证数据
+const aesCredentialsHeader = "alibaba-cloud-credentials/aes-256-gcm/v1"
+
+// ErrCredentialsKeyNotFound 凭证文件已加密，但没有配置解密密钥
+var ErrCredentialsKeyNotFound = errors.New("凭证文件已加密，但没有配置解密密钥")
+
+// CredentialsKey 加密凭证文件的密钥
+/**
+ * 字段为空时依次使用对应的环境变量：
+ * Key 对应 ALIBABA_CLOUD_CREDENTIALS_KEY，KeyFile 对应 ALIBABA_CLOUD_CREDENTIALS_KEY_FILE，
+ * AgeIdentityFile 对应 ALIBABA_CLOUD_CREDENTIALS_AGE_IDENTITY_FILE
+ */
+type CredentialsKey struct {
+	// AES-256 密钥，base64 或 hex 编码，也可以是 32 字节的二进制原始密钥
+	Key []byte
+	// AES-256 密钥文件，内容为 base64 或 hex 编码的密钥，也可以是 32 字节的二进制原始密钥
+	KeyFile string
+	// age 身份文件
+	AgeIdentityFile string
+}
+
+// 获取 AES 密钥
+func (k CredentialsKey) aesKey() ([]byte, error) {
+	if len(k.Key) > 0 {
+		return parseCredentialsKey(k.Key, true)
+	}
+	keyFile := firstNonEmpty(k.KeyFile, os.Getenv(EnvCredentialsKeyFile))
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取凭证密钥文件 %s 失败：%w", keyFile, err)
+		}
+		return parseCredentialsKey(data, true)
+	}
+	if key := os.Getenv(EnvCredentialsKey); key != "" {
+		return parseCredentialsKey([]byte(key), false)
+	}
+	return nil, ErrCredentialsKeyNotFound
+}
+
+// 获取 age 身份
+func (k CredentialsKey) ageIdentities() ([]age.Identity, error) {
+	identityFile := firstNonEmpty(k.AgeIdentityFile, os.Getenv(EnvCredentialsAgeIdentityFile))
+	if identityFile == "" {
+		return nil, ErrCredentialsKeyNotFound
+	}
+	file, err := os.Open(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取 age 身份文件 %s 失败：%w", identityFile, err)
+	}
+	defer file.Close()
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("解析 age 身份文件 %s 失败：%w", identityFile, err)
+	}
+	return identities, nil
+}
+
+// 解析密钥：先去掉首尾空白按 base64、hex 解码；allowRaw 为 true 时也接受 32 字节的二进制原始密钥
+// 原始密钥必须包含非文本字节，避免把恰好 32 个字符的文本（例如带换行的编码密钥、其他长度密钥的编码）误当成原始密钥
+func parseCredentialsKey(data []byte, allowRaw bool) ([]byte, error) {
+	text := strings.TrimSpace(string(data))
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(text); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if allowRaw && len(data) == 32 && isBinaryKey(data) {
+		return data, nil
+	}
+	return nil, errors.New("凭证密钥必须是 32 字节，使用 base64 或 hex 编码")
+}
+
+// 内容是否包含可打印 ASCII 字符和空白以外的字节
+func isBinaryKey(data []byte) bool {
+	for _, b := range data {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' || b > 0x7e {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateCredentialsKey 生成 base64 编码的 AES-256 密钥
+func GenerateCredentialsKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// IsEncryptedCredentials 内容是否为加密的凭证文件（AES-GCM 或 age 格式）
+func IsEncryptedCredentials(data []byte) bool {
+	return isAESCredentials(data) || isAgeCredentials(data)
+}
+
+func isAESCredentials(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(aesCredentialsHeader+"\n"))
+}
+
+func isAgeCredentials(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("age-encryption.org/v1\n")) ||
+		bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header))
+}
+
+// EncryptCredentials
+/** 使用 AES-256-GCM 加密凭证文件
+ * @param plaintext 凭证文件内容，格式同 ProfileCredentialProvider 读取的 INI 文件
+ * @param key base64 或 hex 编码的密钥，也可以是 32 字节的二进制原始密钥
+ * @return []byte 加密后的文件内容
+ * @return error 错误响应对象
+ */
+func EncryptCredentials(plaintext, key []byte) ([]byte, error) {
+	key, err := parseCredentialsKey(key, true)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newCredentialsGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, []byte(aesCredentialsHeader))
+	var out bytes.Buffer
+	out.WriteString(aesCredentialsHeader + "\n")
+	out.WriteString(base64.StdEncoding.EncodeToString(sealed))
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
+
+// EncryptCredentialsAge
+/** 使用 age 格式加密凭证文件，输出为 ASCII armor 文本
+ * @param plaintext 凭证文件内容
+ * @param recipients age 公钥（age1 开头），至少一个
+ * @return []byte 加密后的文件内容
+ * @return error 错误响应对象
+ */
+func EncryptCredentialsAge(plaintext []byte, recipients ...string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("age 加密至少需要一个接收者公钥")
+	}
+	parsed := make([]age.Recipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		r, err := age.ParseX25519Recipient(strings.TrimSpace(recipient))
+		if err != nil {
+			return nil, fmt.Errorf("age 公钥 %s 不合法：%w", recipient, err)
+		}
+		parsed = append(parsed, r)
+	}
+	var out bytes.Buffer
+	armorWriter := armor.NewWriter(&out)
+	writer, err := age.Encrypt(armorWriter, parsed...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = writer.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	if err = armorWriter.Close(); err != nil {
+		return nil, err
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
+
+// DecryptCredentials
+/** 解密凭证文件，根据文件内容自动识别 AES-GCM 或 age 格式；未加密的内容原样返回
+ * @param data 文件内容
+ * @param key 解密密钥，字段为空时使用对应的环境变量
+ * @return []byte 解密后的文件内容
+ * @return error 没有配置密钥时返回 ErrCredentialsKeyNotFound（可包装）
+ */
+func DecryptCredentials(data []byte, key CredentialsKey) ([]byte, error) {
+	switch {
+	case isAESCredentials(data):
+		aesKey, err := key.aesKey()
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data[len(aesCredentialsHeader)+1:])))
+		if err != nil {
+			return nil, fmt.Errorf("加密凭证文件格式不合法：%w", err)
+		}
+		gcm, err := newCredentialsGCM(aesKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(sealed) < gcm.NonceSize() {
+			return nil, errors.New("加密凭证文件格式不合法")
+		}
+		plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(aesCredentialsHeader))
+		if err != nil {
+			return nil, errors.New("解密凭证文件失败，密钥不正确或文件已损坏")
+		}
+		return plaintext, nil
+	case isAgeCredentials(data):
+		identities, err := key.ageIdentities()
+		if err != nil {
+			return nil, err
+		}
+		var src io.Reader = bytes.NewReader(data)
+		if !bytes.HasPrefix(data, []byte("age-encryption.org/v1\n")) {
+			src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+		}
+		reader, err := age.Decrypt(src, identities...)
+		if err != nil {
+			return nil, fmt.Errorf("解密 age 凭证文件失败：%w", err)
+		}
+		return io.ReadAll(reader)
+	default:
+		return data, nil
+	}
+}
+
+func newCredentialsGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptedCredentialProvider 加密凭证文件提供者
+/**
+ * 读取使用 EncryptCredentials 或 EncryptCredentialsAge 加密的凭证文件（可以使用 cmd/sms_credential 命令生成），
+ * 解密后的内容与 ProfileCredentialProvider 读取的 INI 文件格式相同。
+ * 密钥通过 Key 指定，或通过 ALIBABA_CLOUD_CREDENTIALS_KEY、ALIBABA_CLOUD_CREDENTIALS_KEY_FILE、
+ * ALIBABA_CLOUD_CREDENTIALS_AGE_IDENTITY_FILE 环境变量指定。
+ * 与 RotatingCredentialProvider 一起使用时，加密文件更新后可以自动切换凭证
+ */
+type EncryptedCredentialProvider struct {
+	// 加密凭证文件路径
+	Filename string
+	// 配置名称，为空时依次使用 ALIBABA_CLOUD_PROFILE 环境变量和 default
+	Profile string
+	// 解密密钥
+	Key CredentialsKey
+}
+
+func (p *EncryptedCredentialProvider) Retrieve() (*Credential, error) {
+	if p.Filename == "" {
+		return nil, errors.New("加密凭证文件路径不能为空")
+	}
+	data, err := os.ReadFile(p.Filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrCredentialNotFound
+		}
+		return nil, fmt.Errorf("读取加密凭证文件 %s 失败：%w", p.Filename, err)
+	}
+	if !IsEncryptedCredentials(data) {
+		return nil, fmt.Errorf("凭证文件 %s 没有加密", p.Filename)
+	}
+	plaintext, err := DecryptCredentials(data, p.Key)
+	if err != nil {
+		return nil, fmt.Errorf("解密凭证文件 %s 失败：%w", p.Filename, err)
+	}
+	return (&ProfileCredentialProvider{Filename: p.Filename, Profile: p.Profile}).retrieveFrom(p.Filename, plaintext)
+}
@@ -0,0 +1,189 @@
+package alibaba
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const testCredentialsFile = "[default]\naccess_key_id = id\naccess_key_secret = secret\n"
+
+func TestParseCredentialsKey(t *testing.T) {
+	raw := bytes.Repeat([]byte{0xff}, 32)
+	tests := []struct {
+		name     string
+		data     []byte
+		allowRaw bool
+		valid    bool
+	}{
+		{"base64", []byte(base64.StdEncoding.EncodeToString(raw)), false, true},
+		{"base64 with newline", []byte(base64.StdEncoding.EncodeToString(raw) + "\n"), false, true},
+		{"hex", []byte(hex.EncodeToString(raw)), false, true},
+		{"hex with spaces", []byte("  " + hex.EncodeToString(raw) + "\r\n"), false, true},
+		{"binary", raw, true, true},
+		{"binary not allowed", raw, false, false},
+		// 24 字节密钥的 base64 编码恰好 32 个字符，不能当成原始密钥
+		{"base64 AES-192", []byte(base64.StdEncoding.EncodeToString(raw[:24])), true, false},
+		{"32 characters of text", []byte(strings.Repeat("k", 31) + "\n"), true, false},
+		{"short", []byte(base64.StdEncoding.EncodeToString(raw[:16])), true, false},
+	}
+	for _, tt := range tests {
+		key, err := parseCredentialsKey(tt.data, tt.allowRaw)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: error = %v, want valid %v", tt.name, err, tt.valid)
+			continue
+		}
+		if err == nil && !bytes.Equal(key, raw) {
+			t.Errorf("%s: key = %x", tt.name, key)
+		}
+	}
+}
+
+func TestAESCredentials(t *testing.T) {
+	key, err := GenerateCredentialsKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := EncryptCredentials([]byte(testCredentialsFile), []byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncryptedCredentials(encrypted) || bytes.Contains(encrypted, []byte("secret")) {
+		t.Fatalf("encrypted = %s", encrypted)
+	}
+
+	// 密钥通过 Key、密钥文件和环境变量传入
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte(key+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for name, credentialsKey := range map[string]CredentialsKey{"key": {Key: []byte(key)}, "key file": {KeyFile: keyFile}} {
+		plaintext, err := DecryptCredentials(encrypted, credentialsKey)
+		if err != nil || string(plaintext) != testCredentialsFile {
+			t.Errorf("%s: DecryptCredentials() = %q, %v", name, plaintext, err)
+		}
+	}
+	t.Setenv(EnvCredentialsKey, key)
+	if plaintext, err := DecryptCredentials(encrypted, CredentialsKey{}); err != nil || string(plaintext) != testCredentialsFile {
+		t.Errorf("env: DecryptCredentials() = %q, %v", plaintext, err)
+	}
+
+	otherKey, _ := GenerateCredentialsKey()
+	if _, err := DecryptCredentials(encrypted, CredentialsKey{Key: []byte(otherKey)}); err == nil {
+		t.Error("wrong key decrypted the file")
+	}
+
+	// 首行是附加认证数据：修改首行后不再被识别为加密文件，也不会解出明文
+	tampered := bytes.Replace(encrypted, []byte("/v1\n"), []byte("/v2\n"), 1)
+	if plaintext, _ := DecryptCredentials(tampered, CredentialsKey{Key: []byte(key)}); bytes.Contains(plaintext, []byte("secret")) {
+		t.Error("tampered header was decrypted")
+	}
+	filename := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(filename, tampered, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&EncryptedCredentialProvider{Filename: filename, Key: CredentialsKey{Key: []byte(key)}}).Retrieve(); err == nil {
+		t.Error("tampered header was accepted")
+	}
+
+	// 修改密文
+	sealed, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encrypted[len(aesCredentialsHeader)+1:])))
+	sealed[len(sealed)-1] ^= 1
+	tampered = []byte(aesCredentialsHeader + "\n" + base64.StdEncoding.EncodeToString(sealed) + "\n")
+	if _, err := DecryptCredentials(tampered, CredentialsKey{Key: []byte(key)}); err == nil {
+		t.Error("tampered ciphertext was decrypted")
+	}
+
+	if _, err := DecryptCredentials(encrypted, CredentialsKey{KeyFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("missing key file was accepted")
+	}
+}
+
+func TestEncryptedCredentialProvider(t *testing.T) {
+	key, _ := GenerateCredentialsKey()
+	encrypted, err := EncryptCredentials([]byte(testCredentialsFile), []byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(filename, encrypted, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	credential, err := (&EncryptedCredentialProvider{Filename: filename, Key: CredentialsKey{Key: []byte(key)}}).Retrieve()
+	if err != nil || credential.AccessKeyId != "id" || credential.AccessKeySecret != "secret" {
+		t.Fatalf("Retrieve() = %+v, %v", credential, err)
+	}
+
+	t.Setenv(EnvCredentialsKey, "")
+	t.Setenv(EnvCredentialsKeyFile, "")
+	if _, err := (&EncryptedCredentialProvider{Filename: filename}).Retrieve(); err == nil {
+		t.Fatal("file was decrypted without a key")
+	}
+	plain := filepath.Join(t.TempDir(), "plain")
+	if err := os.WriteFile(plain, []byte(testCredentialsFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&EncryptedCredentialProvider{Filename: plain, Key: CredentialsKey{Key: []byte(key)}}).Retrieve(); err == nil {
+		t.Fatal("plaintext file was accepted")
+	}
+}
+
+func TestAgeCredentials(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	identityFile := filepath.Join(t.TempDir(), "identity.txt")
+	if err := os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := EncryptCredentialsAge([]byte(testCredentialsFile), identity.Recipient().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncryptedCredentials(encrypted) {
+		t.Fatalf("encrypted = %s", encrypted)
+	}
+	plaintext, err := DecryptCredentials(encrypted, CredentialsKey{AgeIdentityFile: identityFile})
+	if err != nil || string(plaintext) != testCredentialsFile {
+		t.Fatalf("DecryptCredentials() = %q, %v", plaintext, err)
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	otherFile := filepath.Join(t.TempDir(), "other.txt")
+	if err := os.WriteFile(otherFile, []byte(other.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptCredentials(encrypted, CredentialsKey{AgeIdentityFile: otherFile}); err == nil {
+		t.Error("wrong identity decrypted the file")
+	}
+
+	// 修改 age 文件头，文件头由 MAC 保护
+	binary, err := io.ReadAll(armor.NewReader(bytes.NewReader(bytes.TrimSpace(encrypted))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := bytes.Index(binary, []byte("\n---"))
+	if end < 0 {
+		t.Fatal("age header not found")
+	}
+	binary[end-1] ^= 1
+	if _, err := DecryptCredentials(binary, CredentialsKey{AgeIdentityFile: identityFile}); err == nil {
+		t.Error("tampered age header was decrypted")
+	}
+
+	if _, err := EncryptCredentialsAge([]byte(testCredentialsFile)); err == nil {
+		t.Error("no recipients was accepted")
+	}
+	if _, err := EncryptCredentialsAge([]byte(testCredentialsFile), "age1invalid"); err == nil {
+		t.Error("invalid recipient was accepted")
+	}
+}
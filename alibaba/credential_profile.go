@@ -0,0 +1,122 @@
+package alibaba
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	// EnvCredentialsFile 环境变量：凭证配置文件路径
+	EnvCredentialsFile = "ALIBABA_CLOUD_CREDENTIALS_FILE"
+	// EnvProfile 环境变量：凭证配置文件中使用的配置名称
+	EnvProfile = "ALIBABA_CLOUD_PROFILE"
+)
+
+// ProfileCredentialProvider 配置文件凭证提供者
+/**
+ * 读取标准的 ~/.alibabacloud/credentials INI 配置文件，例如：
+ *	[default]
+ *	type = access_key
+ *	access_key_id = xxx
+ *	access_key_secret = xxx
+ * 支持的 type：access_key、sts（需要 security_token）、ecs_ram_role（需要 role_name）、
+ * oidc_role_arn（需要 role_arn、oidc_provider_arn、oidc_token_file_path，可选 role_session_name）、
+ * ram_role_arn（需要 access_key_id、access_key_secret、role_arn，可选 role_session_name、policy、external_id）
+ * 配置文件也可以是加密的（见 EncryptedCredentialProvider），解密密钥从环境变量中读取
+ */
+type ProfileCredentialProvider struct {
+	// 配置文件路径，为空时依次使用 ALIBABA_CLOUD_CREDENTIALS_FILE 环境变量和 ~/.alibabacloud/credentials
+	Filename string
+	// 配置名称，为空时依次使用 ALIBABA_CLOUD_PROFILE 环境变量和 default
+	Profile string
+}
+
+func (p *ProfileCredentialProvider) filename() string {
+	if p.Filename != "" {
+		return p.Filename
+	}
+	if filename := os.Getenv(EnvCredentialsFile); filename != "" {
+		return filename
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".alibabacloud", "credentials")
+}
+
+func (p *ProfileCredentialProvider) profile() string {
+	if p.Profile != "" {
+		return p.Profile
+	}
+	if profile := os.Getenv(EnvProfile); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+func (p *ProfileCredentialProvider) Retrieve() (*Credential, error) {
+	filename := p.filename()
+	if filename == "" {
+		return nil, ErrCredentialNotFound
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrCredentialNotFound
+		}
+		return nil, fmt.Errorf("读取凭证配置文件 %s 失败：%w", filename, err)
+	}
+	// 加密的凭证文件使用环境变量中的密钥解密
+	if IsEncryptedCredentials(data) {
+		if data, err = DecryptCredentials(data, CredentialsKey{}); err != nil {
+			return nil, fmt.Errorf("解密凭证配置文件 %s 失败：%w", filename, err)
+		}
+	}
+	return p.retrieveFrom(filename, data)
+}
+
+// 从凭证配置文件内容中读取凭证
+func (p *ProfileCredentialProvider) retrieveFrom(filename string, data []byte) (*Credential, error) {
+	file, err := ini.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析凭证配置文件 %s 失败：%w", filename, err)
+	}
+	section, err := file.GetSection(p.profile())
+	if err != nil {
+		return nil, fmt.Errorf("凭证配置文件 %s 中不存在配置 %s：%w", filename, p.profile(), ErrCredentialNotFound)
+	}
+	value := func(key string) string {
+		return strings.TrimSpace(section.Key(key).String())
+	}
+	switch value("type") {
+	case "", "access_key":
+		return NewStaticCredentialProvider(value("access_key_id"), value("access_key_secret"), "").Retrieve()
+	case "sts":
+		return NewStaticCredentialProvider(value("access_key_id"), value("access_key_secret"), value("security_token")).Retrieve()
+	case "ecs_ram_role":
+		return (&EcsRamRoleCredentialProvider{RoleName: value("role_name")}).Retrieve()
+	case "oidc_role_arn":
+		return (&OIDCRoleArnCredentialProvider{
+			RoleArn:         value("role_arn"),
+			OIDCProviderArn: value("oidc_provider_arn"),
+			OIDCTokenFile:   value("oidc_token_file_path"),
+			RoleSessionName: value("role_session_name"),
+		}).Retrieve()
+	case "ram_role_arn":
+		return (&AssumeRoleCredentialProvider{
+			Source:          NewStaticCredentialProvider(value("access_key_id"), value("access_key_secret"), ""),
+			RoleArn:         value("role_arn"),
+			RoleSessionName: value("role_session_name"),
+			Policy:          value("policy"),
+			ExternalId:      value("external_id"),
+		}).Retrieve()
+	default:
+		return nil, fmt.Errorf("凭证配置文件 %s 中的凭证类型 %s 不受支持", filename, value("type"))
+	}
+}
@@ -0,0 +1,238 @@
+package alibaba
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// 凭证轮换的触发来源
+const (
+	// RotationTriggerManual 调用 Reload 或 Rotate 触发
+	RotationTriggerManual = "manual"
+	// RotationTriggerFile 凭证文件变化触发
+	RotationTriggerFile = "file"
+	// RotationTriggerSignal 收到信号触发
+	RotationTriggerSignal = "signal"
+	// RotationTriggerExpiry 凭证即将过期触发
+	RotationTriggerExpiry = "expiry"
+)
+
+// CredentialProviderFunc 将函数适配为凭证提供者，可用于从配置中心、密钥管理服务等自定义来源获取凭证
+type CredentialProviderFunc func() (*Credential, error)
+
+func (f CredentialProviderFunc) Retrieve() (*Credential, error) {
+	return f()
+}
+
+// RotationEvent 凭证轮换事件
+type RotationEvent struct {
+	// 触发来源，见 RotationTriggerManual 等常量
+	Trigger string
+	// 轮换前的 AccessKey ID
+	OldAccessKeyId string
+	// 轮换后的 AccessKey ID，轮换失败时为空
+	NewAccessKeyId string
+	// 轮换失败的原因，失败时继续使用旧凭证
+	Err error
+	// 事件时间
+	Time time.Time
+}
+
+// RotatingCredentialProvider 可热轮换的凭证提供者
+/**
+ * 从 source 加载凭证并保存为当前凭证，文件变化、收到信号或调用 Reload/Rotate 时原子地切换到新凭证，无需重启进程。
+ * NewClientWithProvider 创建的客户端每次请求取一份当前凭证用于签名，已经发出的请求继续使用旧凭证完成，切换后的新请求使用新凭证；
+ * CreateClientWithProvider 返回的 SDK 客户端签名时分别读取 AK、Secret 和 Token，切换瞬间的请求可能签名失败，不建议与轮换一起使用。
+ * 例如 AccessKey 保存在凭证配置文件中，文件更新后自动切换：
+ *	provider, err := alibaba.NewRotatingCredentialProvider(&alibaba.ProfileCredentialProvider{Filename: filename})
+ *	provider.WatchFile(filename, 10*time.Second)
+ *	provider.OnRotate(func(e alibaba.RotationEvent) { log.Println(e.Trigger, e.NewAccessKeyId, e.Err) })
+ *	client, err := alibaba.NewClientWithProvider(provider)
+ */
+type RotatingCredentialProvider struct {
+	source  CredentialProvider
+	current atomic.Pointer[Credential]
+	// 串行化重新加载
+	reloadMu sync.Mutex
+
+	hooksMu sync.RWMutex
+	hooks   []func(RotationEvent)
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewRotatingCredentialProvider
+/** 创建可热轮换的凭证提供者，创建时从 source 加载一次凭证
+ * @param source 凭证来源，例如 ProfileCredentialProvider、CredentialProviderFunc
+ * @return *RotatingCredentialProvider 凭证提供者，不再使用时调用 Close 停止监听
+ * @return error 首次加载凭证失败时返回错误
+ */
+func NewRotatingCredentialProvider(source CredentialProvider) (*RotatingCredentialProvider, error) {
+	if source == nil {
+		return nil, errors.New("凭证来源不能为空")
+	}
+	credential, err := source.Retrieve()
+	if err != nil {
+		return nil, err
+	}
+	p := &RotatingCredentialProvider{source: source, stop: make(chan struct{})}
+	p.current.Store(credential)
+	return p, nil
+}
+
+// Retrieve 返回当前凭证，当前凭证即将过期时先从来源重新加载
+func (p *RotatingCredentialProvider) Retrieve() (*Credential, error) {
+	credential := p.current.Load()
+	if credential.expiresWithin(credentialExpiryWindow) {
+		if err := p.reload(RotationTriggerExpiry); err != nil {
+			return nil, err
+		}
+		credential = p.current.Load()
+	}
+	return credential, nil
+}
+
+// 由轮换提供者自行维护当前凭证，SDK 适配器不再缓存，每次请求签名时取一次
+func (p *RotatingCredentialProvider) live() {}
+
+// OnRotate
+/** 注册凭证轮换的回调，凭证切换成功或重新加载失败时调用；凭证内容没有变化时不调用
+ * 回调在触发轮换的 goroutine 中同步执行，不应长时间阻塞
+ * @param hook 回调函数
+ */
+func (p *RotatingCredentialProvider) OnRotate(hook func(RotationEvent)) {
+	p.hooksMu.Lock()
+	defer p.hooksMu.Unlock()
+	p.hooks = append(p.hooks, hook)
+}
+
+// Reload
+/** 立即从来源重新加载凭证，例如在收到配置中心的变更通知后调用
+ * @return error 加载失败时返回错误，继续使用旧凭证
+ */
+func (p *RotatingCredentialProvider) Reload() error {
+	return p.reload(RotationTriggerManual)
+}
+
+// Rotate
+/** 直接切换到指定的凭证，不经过来源
+ * @param credential 新凭证
+ * @return error 凭证不完整时返回错误
+ */
+func (p *RotatingCredentialProvider) Rotate(credential Credential) error {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	if credential.AccessKeyId == "" || credential.AccessKeySecret == "" {
+		err := errors.New("轮换的凭证不完整")
+		p.notify(RotationEvent{Trigger: RotationTriggerManual, OldAccessKeyId: p.current.Load().AccessKeyId, Err: err})
+		return err
+	}
+	p.swap(RotationTriggerManual, &credential)
+	return nil
+}
+
+func (p *RotatingCredentialProvider) reload(trigger string) error {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	credential, err := p.source.Retrieve()
+	if err != nil {
+		p.notify(RotationEvent{Trigger: trigger, OldAccessKeyId: p.current.Load().AccessKeyId, Err: err})
+		return err
+	}
+	p.swap(trigger, credential)
+	return nil
+}
+
+// 切换到新凭证，调用方持有 reloadMu
+func (p *RotatingCredentialProvider) swap(trigger string, credential *Credential) {
+	old := p.current.Load()
+	if old.AccessKeyId == credential.AccessKeyId && old.AccessKeySecret == credential.AccessKeySecret &&
+		old.SecurityToken == credential.SecurityToken && old.Expiration.Equal(credential.Expiration) {
+		return
+	}
+	p.current.Store(credential)
+	p.notify(RotationEvent{Trigger: trigger, OldAccessKeyId: old.AccessKeyId, NewAccessKeyId: credential.AccessKeyId})
+}
+
+func (p *RotatingCredentialProvider) notify(event RotationEvent) {
+	event.Time = time.Now()
+	p.hooksMu.RLock()
+	defer p.hooksMu.RUnlock()
+	for _, hook := range p.hooks {
+		hook(event)
+	}
+}
+
+// WatchFile
+/** 定期检查文件的修改时间和大小，变化时从来源重新加载凭证
+ * 文件暂时不存在（例如正在被替换）时跳过本次检查
+ * @param filename 需要监听的文件，通常是来源读取的凭证文件
+ * @param interval 检查间隔，小于等于 0 时使用 10 秒
+ */
+func (p *RotatingCredentialProvider) WatchFile(filename string, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	var modTime time.Time
+	var size int64
+	if info, err := os.Stat(filename); err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				info, err := os.Stat(filename)
+				if err != nil || info.ModTime().Equal(modTime) && info.Size() == size {
+					continue
+				}
+				modTime, size = info.ModTime(), info.Size()
+				_ = p.reload(RotationTriggerFile)
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// WatchSignal
+/** 收到信号时从来源重新加载凭证，例如运维更新凭证文件后执行 kill -HUP <pid>
+ * @param signals 需要监听的信号，为空时监听 SIGHUP
+ */
+func (p *RotatingCredentialProvider) WatchSignal(signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ch:
+				_ = p.reload(RotationTriggerSignal)
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close 停止所有文件和信号监听，当前凭证仍可继续使用，Reload 和 Rotate 仍然有效；关闭后再调用 WatchFile、WatchSignal 不会生效
+func (p *RotatingCredentialProvider) Close() {
+	p.closeOnce.Do(func() { close(p.stop) })
+	p.wg.Wait()
+}
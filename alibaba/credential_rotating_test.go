@@ -0,0 +1,217 @@
+package alibaba
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+func TestRotatingCredentialProviderConcurrentRotate(t *testing.T) {
+	provider, err := NewRotatingCredentialProvider(NewStaticCredentialProvider("id-0", "secret-0", "token-0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClientWithProvider(provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	var rotations sync.WaitGroup
+	rotations.Add(1)
+	go func() {
+		defer rotations.Done()
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			n := strconv.Itoa(i)
+			if err := provider.Rotate(Credential{AccessKeyId: "id-" + n, AccessKeySecret: "secret-" + n, SecurityToken: "token-" + n}); err != nil {
+				t.Error(err)
+				return
+			}
+			runtime.Gosched()
+		}
+	}()
+
+	var signers sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		signers.Add(1)
+		go func() {
+			defer signers.Done()
+			for j := 0; j < 2000; j++ {
+				checkSigningCredential(t, client.smsClient().Credential)
+			}
+		}()
+	}
+	signers.Wait()
+	close(stop)
+	rotations.Wait()
+}
+
+func TestRotatingCredentialProviderEvents(t *testing.T) {
+	var mu sync.Mutex
+	credential := &Credential{AccessKeyId: "id-1", AccessKeySecret: "secret-1"}
+	var sourceErr error
+	source := CredentialProviderFunc(func() (*Credential, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return credential, sourceErr
+	})
+	provider, err := NewRotatingCredentialProvider(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []RotationEvent
+	provider.OnRotate(func(e RotationEvent) { events = append(events, e) })
+
+	// 凭证没有变化时不通知
+	if err = provider.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	credential = &Credential{AccessKeyId: "id-2", AccessKeySecret: "secret-2"}
+	mu.Unlock()
+	if err = provider.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	sourceErr = errors.New("配置中心不可用")
+	mu.Unlock()
+	if err = provider.Reload(); err == nil {
+		t.Fatal("Reload() with failing source succeeded")
+	}
+	if err = provider.Rotate(Credential{AccessKeyId: "id-3"}); err == nil {
+		t.Fatal("Rotate() with incomplete credential succeeded")
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(events), events)
+	}
+	if events[0].OldAccessKeyId != "id-1" || events[0].NewAccessKeyId != "id-2" || events[0].Err != nil {
+		t.Fatalf("events[0] = %+v", events[0])
+	}
+	if events[1].Err == nil || events[2].Err == nil {
+		t.Fatalf("failed reloads were not reported: %+v", events[1:])
+	}
+	// 失败后继续使用旧凭证
+	current, err := provider.Retrieve()
+	if err != nil || current.AccessKeyId != "id-2" {
+		t.Fatalf("Retrieve() = %+v, %v, want id-2", current, err)
+	}
+}
+
+func TestRotatingCredentialProviderWatchFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "credentials")
+	writeProfile := func(accessKeyId string) {
+		t.Helper()
+		data := fmt.Sprintf("[default]\naccess_key_id = %s\naccess_key_secret = secret-%s\n", accessKeyId, accessKeyId)
+		if err := os.WriteFile(filename, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeProfile("id-1")
+	provider, err := NewRotatingCredentialProvider(&ProfileCredentialProvider{Filename: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer provider.Close()
+	rotated := make(chan RotationEvent, 1)
+	provider.OnRotate(func(e RotationEvent) {
+		if e.Err == nil {
+			select {
+			case rotated <- e:
+			default:
+			}
+		}
+	})
+	provider.WatchFile(filename, 10*time.Millisecond)
+
+	// 文件大小变化，避免依赖文件系统的修改时间精度
+	writeProfile("id-22")
+	select {
+	case e := <-rotated:
+		if e.Trigger != RotationTriggerFile || e.NewAccessKeyId != "id-22" {
+			t.Fatalf("event = %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("file change was not picked up")
+	}
+}
+
+func TestCreateClientWithProviderRotation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// v3 签名的 AccessKey ID 在 Authorization 中，安全令牌在单独的请求头中，两者必须来自同一份凭证
+		authorization := r.Header.Get("Authorization")
+		token := r.Header.Get("x-acs-security-token")
+		n := strings.TrimPrefix(token, "token-")
+		if !strings.Contains(authorization, "Credential=id-"+n+",") {
+			t.Errorf("signed with %s and %s", authorization, token)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(okResponse("biz"))
+	}))
+	defer server.Close()
+
+	provider, err := NewRotatingCredentialProvider(NewStaticCredentialProvider("id-0", "secret-0", "token-0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := CreateClientWithProvider(provider, WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	var rotations sync.WaitGroup
+	rotations.Add(1)
+	go func() {
+		defer rotations.Done()
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			n := strconv.Itoa(i)
+			if err := provider.Rotate(Credential{AccessKeyId: "id-" + n, AccessKeySecret: "secret-" + n, SecurityToken: "token-" + n}); err != nil {
+				t.Error(err)
+				return
+			}
+			runtime.Gosched()
+		}
+	}()
+
+	var senders sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		senders.Add(1)
+		go func() {
+			defer senders.Done()
+			for j := 0; j < 50; j++ {
+				request := &dysmsapi20170525.SendSmsRequest{PhoneNumbers: tea.String("13800000000"), SignName: tea.String("测试签名"), TemplateCode: tea.String("SMS_1")}
+				if _, err := client.SendSms(request); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	senders.Wait()
+	close(stop)
+	rotations.Wait()
+}
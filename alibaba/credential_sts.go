@@ -0,0 +1,101 @@
+package alibaba
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	openapiutil "github.com/alibabacloud-go/openapi-util/service"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// AssumeRoleCredentialProvider STS AssumeRole 凭证提供者
+/**
+ * 使用源凭证（通常是只拥有 sts:AssumeRole 权限的 RAM 用户 AccessKey）调用 STS AssumeRole 扮演 RAM 角色，获取临时凭证
+ * 临时凭证由客户端在过期前自动刷新，刷新在后台进行，期间正在发送的请求继续使用旧凭证，不会被中断
+ * API文档地址：https://help.aliyun.com/document_detail/371864.html
+ */
+type AssumeRoleCredentialProvider struct {
+	// 源凭证提供者
+	Source CredentialProvider
+	// 要扮演的 RAM 角色 ARN，例如 acs:ram::123456789012****:role/sms-sender
+	RoleArn string
+	// 角色会话名称，为空时自动生成
+	RoleSessionName string
+	// 临时凭证有效期（秒），取值范围 900 ~ 角色最大会话时间，为 0 时使用 STS 默认值 3600
+	DurationSeconds int
+	// 权限策略，可进一步限制临时凭证的权限
+	Policy string
+	// 角色外部 ID，用于防止混淆代理人问题
+	ExternalId string
+	// STS 服务地址，为空时使用 DefaultStsEndpoint，测试时可指向本地的模拟服务
+	StsEndpoint string
+	// 访问 STS 使用的 http 客户端，为空时使用默认客户端
+	HTTPClient *http.Client
+}
+
+func (p *AssumeRoleCredentialProvider) Retrieve() (*Credential, error) {
+	if p.Source == nil {
+		return nil, errors.New("STS AssumeRole 缺少源凭证")
+	}
+	if p.RoleArn == "" {
+		return nil, errors.New("STS AssumeRole 缺少 RoleArn")
+	}
+	if p.DurationSeconds != 0 && p.DurationSeconds < 900 {
+		return nil, errors.New("STS AssumeRole 临时凭证有效期不能小于 900 秒")
+	}
+	source, err := p.Source.Retrieve()
+	if err != nil {
+		return nil, fmt.Errorf("获取 STS AssumeRole 源凭证失败：%w", err)
+	}
+
+	params := map[string]*string{
+		"Action":           tea.String("AssumeRole"),
+		"Format":           tea.String("JSON"),
+		"Version":          tea.String("2015-04-01"),
+		"Timestamp":        openapiutil.GetTimestamp(),
+		"SignatureMethod":  tea.String("HMAC-SHA1"),
+		"SignatureVersion": tea.String("1.0"),
+		"SignatureNonce":   util.GetNonce(),
+		"AccessKeyId":      tea.String(source.AccessKeyId),
+		"RoleArn":          tea.String(p.RoleArn),
+		"RoleSessionName":  tea.String(firstNonEmpty(p.RoleSessionName, defaultRoleSessionName())),
+	}
+	if source.SecurityToken != "" {
+		params["SecurityToken"] = tea.String(source.SecurityToken)
+	}
+	if p.DurationSeconds > 0 {
+		params["DurationSeconds"] = tea.String(strconv.Itoa(p.DurationSeconds))
+	}
+	if p.Policy != "" {
+		params["Policy"] = tea.String(p.Policy)
+	}
+	if p.ExternalId != "" {
+		params["ExternalId"] = tea.String(p.ExternalId)
+	}
+	params["Signature"] = openapiutil.GetRPCSignature(params, tea.String("POST"), tea.String(source.AccessKeySecret))
+
+	form := url.Values{}
+	for key, value := range params {
+		form.Set(key, tea.StringValue(value))
+	}
+	client := p.HTTPClient
+	if client == nil {
+		client = credentialHTTPClient
+	}
+	resp, err := client.PostForm(strings.TrimRight(firstNonEmpty(p.StsEndpoint, DefaultStsEndpoint), "/")+"/", form)
+	if err != nil {
+		return nil, fmt.Errorf("调用 STS AssumeRole 失败：%w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("调用 STS AssumeRole 失败：%w", err)
+	}
+	return parseStsCredentials("AssumeRole", body)
+}
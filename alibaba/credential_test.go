@@ -0,0 +1,249 @@
+package alibaba
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	credentials "github.com/aliyun/credentials-go/credentials"
+)
+
+// 本地模拟的 ECS 实例元数据服务
+func newMetadataServer(t *testing.T, roleName string, expiration time.Time) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ecsSecurityCredentialsPath:
+			fmt.Fprint(w, roleName)
+		case ecsSecurityCredentialsPath + roleName:
+			fmt.Fprintf(w, `{"Code":"Success","AccessKeyId":"STS.id","AccessKeySecret":"secret","SecurityToken":"token","Expiration":%q}`,
+				expiration.UTC().Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEcsRamRoleCredentialProvider(t *testing.T) {
+	t.Setenv(EnvEcsMetadata, "")
+	expiration := time.Now().Add(time.Hour).Truncate(time.Second)
+	server := newMetadataServer(t, "sms-sender", expiration)
+
+	provider := &EcsRamRoleCredentialProvider{MetadataEndpoint: server.URL}
+	credential, err := provider.Retrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Credential{AccessKeyId: "STS.id", AccessKeySecret: "secret", SecurityToken: "token", Expiration: expiration}
+	if credential.AccessKeyId != want.AccessKeyId || credential.AccessKeySecret != want.AccessKeySecret ||
+		credential.SecurityToken != want.SecurityToken || !credential.Expiration.Equal(want.Expiration) {
+		t.Fatalf("Retrieve() = %+v, want %+v", *credential, want)
+	}
+
+	provider = &EcsRamRoleCredentialProvider{RoleName: "other", MetadataEndpoint: server.URL}
+	if _, err = provider.Retrieve(); err == nil {
+		t.Fatal("Retrieve() with unknown role succeeded")
+	}
+}
+
+func TestEcsRamRoleCredentialProviderWithoutRole(t *testing.T) {
+	t.Setenv(EnvEcsMetadata, "")
+	server := newMetadataServer(t, "", time.Now().Add(time.Hour))
+
+	_, err := (&EcsRamRoleCredentialProvider{MetadataEndpoint: server.URL}).Retrieve()
+	if !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("Retrieve() error = %v, want ErrCredentialNotFound", err)
+	}
+}
+
+type countingProvider struct {
+	credential *Credential
+	err        error
+	calls      int
+}
+
+func (p *countingProvider) Retrieve() (*Credential, error) {
+	p.calls++
+	return p.credential, p.err
+}
+
+func TestCredentialChain(t *testing.T) {
+	missing := &countingProvider{err: ErrCredentialNotFound}
+	found := &countingProvider{credential: &Credential{AccessKeyId: "id", AccessKeySecret: "secret"}}
+	unused := &countingProvider{credential: &Credential{AccessKeyId: "unused", AccessKeySecret: "unused"}}
+	chain := NewCredentialChain(missing, found, unused)
+
+	for i := 0; i < 2; i++ {
+		credential, err := chain.Retrieve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if credential.AccessKeyId != "id" {
+			t.Fatalf("Retrieve() AccessKeyId = %q, want id", credential.AccessKeyId)
+		}
+	}
+	// 第一个成功的提供者被记住，之后不再尝试其他提供者
+	if missing.calls != 1 || found.calls != 2 || unused.calls != 0 {
+		t.Fatalf("calls = %d, %d, %d, want 1, 2, 0", missing.calls, found.calls, unused.calls)
+	}
+}
+
+func TestCredentialChainErrors(t *testing.T) {
+	chain := NewCredentialChain(&countingProvider{err: ErrCredentialNotFound})
+	if _, err := chain.Retrieve(); !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("Retrieve() error = %v, want ErrCredentialNotFound", err)
+	}
+
+	broken := errors.New("元数据服务不可用")
+	chain = NewCredentialChain(&countingProvider{err: ErrCredentialNotFound}, &countingProvider{err: broken})
+	_, err := chain.Retrieve()
+	if err == nil || errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("Retrieve() error = %v, want the provider error", err)
+	}
+}
+
+func TestEnvCredentialProvider(t *testing.T) {
+	t.Setenv(EnvAccessKeyId, "")
+	t.Setenv(EnvAccessKeySecret, "")
+	if _, err := (&EnvCredentialProvider{}).Retrieve(); !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("Retrieve() error = %v, want ErrCredentialNotFound", err)
+	}
+
+	t.Setenv(EnvAccessKeyId, " id ")
+	t.Setenv(EnvAccessKeySecret, "secret")
+	t.Setenv(EnvSecurityToken, "token")
+	credential, err := (&EnvCredentialProvider{}).Retrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credential.AccessKeyId != "id" || credential.AccessKeySecret != "secret" || credential.SecurityToken != "token" {
+		t.Fatalf("Retrieve() = %+v", *credential)
+	}
+}
+
+// 每次获取都返回新凭证的提供者，凭证处于刷新窗口内，每次签名都会触发后台刷新
+type sequenceProvider struct {
+	n atomic.Int64
+}
+
+func (p *sequenceProvider) Retrieve() (*Credential, error) {
+	n := strconv.FormatInt(p.n.Add(1), 10)
+	return &Credential{
+		AccessKeyId:     "id-" + n,
+		AccessKeySecret: "secret-" + n,
+		SecurityToken:   "token-" + n,
+		Expiration:      time.Now().Add(credentialRefreshWindow / 2),
+	}, nil
+}
+
+// 按 SDK 签名的顺序分别读取 AK、Secret 和 Token，检查是否来自同一份凭证
+func checkSigningCredential(t *testing.T, credential credentials.Credential) {
+	accessKeyId, err := credential.GetAccessKeyId()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// 让出调度，模拟 SDK 在几次读取之间的其他工作，给后台刷新切换凭证的机会
+	runtime.Gosched()
+	accessKeySecret, _ := credential.GetAccessKeySecret()
+	runtime.Gosched()
+	securityToken, _ := credential.GetSecurityToken()
+	n := strings.TrimPrefix(*accessKeyId, "id-")
+	if *accessKeySecret != "secret-"+n || *securityToken != "token-"+n {
+		t.Errorf("signed with %s, %s, %s", *accessKeyId, *accessKeySecret, *securityToken)
+	}
+}
+
+func TestClientCredentialSnapshotDuringRefresh(t *testing.T) {
+	provider := &sequenceProvider{}
+	client, err := NewClientWithProvider(provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				checkSigningCredential(t, client.smsClient().Credential)
+				checkSigningCredential(t, client.globeClient().Credential)
+			}
+		}()
+	}
+	wg.Wait()
+	if provider.n.Load() < 2 {
+		t.Fatal("credential was never refreshed")
+	}
+}
+
+func TestProviderCredentialSyncRefresh(t *testing.T) {
+	var calls atomic.Int64
+	expired := CredentialProviderFunc(func() (*Credential, error) {
+		calls.Add(1)
+		return &Credential{AccessKeyId: "id", AccessKeySecret: "secret", Expiration: time.Now().Add(time.Second)}, nil
+	})
+	credential := newProviderCredential(expired)
+	for i := 0; i < 3; i++ {
+		if _, err := credential.resolve(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 凭证已进入过期窗口，每次都同步重新获取
+	if calls.Load() != 3 {
+		t.Fatalf("Retrieve calls = %d, want 3", calls.Load())
+	}
+
+	failing := newProviderCredential(CredentialProviderFunc(func() (*Credential, error) {
+		return nil, ErrCredentialNotFound
+	}))
+	if _, err := failing.snapshot().GetAccessKeyId(); !errors.Is(err, ErrCredentialNotFound) {
+		t.Fatalf("GetAccessKeyId() error = %v, want ErrCredentialNotFound", err)
+	}
+}
+
+func TestAssumeRoleCredentialProvider(t *testing.T) {
+	expiration := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if r.Form.Get("Action") != "AssumeRole" || r.Form.Get("RoleArn") != "acs:ram::1:role/sms" ||
+			r.Form.Get("AccessKeyId") != "source-id" || r.Form.Get("Signature") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"Code":"InvalidParameter","Message":"bad request","RequestId":"1"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"RequestId":"1","Credentials":{"AccessKeyId":"STS.id","AccessKeySecret":"secret","SecurityToken":"token","Expiration":%q}}`,
+			expiration.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	provider := &AssumeRoleCredentialProvider{
+		Source:      NewStaticCredentialProvider("source-id", "source-secret", ""),
+		RoleArn:     "acs:ram::1:role/sms",
+		StsEndpoint: server.URL,
+	}
+	credential, err := provider.Retrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credential.AccessKeyId != "STS.id" || credential.SecurityToken != "token" || !credential.Expiration.Equal(expiration) {
+		t.Fatalf("Retrieve() = %+v", *credential)
+	}
+
+	provider.RoleArn = "acs:ram::1:role/other"
+	if _, err = provider.Retrieve(); err == nil {
+		t.Fatal("Retrieve() with rejected request succeeded")
+	}
+}
@@ -0,0 +1,180 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"third_party_tool_library"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// DefaultDedupTTL 幂等键的默认保留时间
+const DefaultDedupTTL = 24 * time.Hour
+
+// ErrSendInProgress 相同幂等键的短信正在发送，或上一次发送的结果未知
+var ErrSendInProgress = errors.New("相同幂等键的短信正在发送或上一次发送结果未知")
+
+// DedupRecord 幂等键对应的发送结果
+type DedupRecord struct {
+	// 接口响应编码
+	StatusCode int32
+	// 业务编码
+	Code string
+	// 业务信息
+	Message string
+	// 请求 ID
+	RequestId string
+	// 发送回执 ID
+	BizId string
+}
+
+func newDedupRecord(statusCode int32, result third_party_tool_library.ResponseResult) DedupRecord {
+	return DedupRecord{
+		StatusCode: statusCode,
+		Code:       tea.StringValue(result.Code),
+		Message:    tea.StringValue(result.Message),
+		RequestId:  tea.StringValue(result.RequestId),
+		BizId:      tea.StringValue(result.BizId),
+	}
+}
+
+// 转换为响应对象
+func (r *DedupRecord) result() third_party_tool_library.ResponseResult {
+	return third_party_tool_library.ResponseResult{
+		Code:      tea.String(r.Code),
+		Message:   tea.String(r.Message),
+		RequestId: tea.String(r.RequestId),
+		BizId:     tea.String(r.BizId),
+	}
+}
+
+// DedupStore 幂等记录存储
+/**
+ * 用于在重试时识别重复的发送请求，默认提供内存实现 MemoryDedupStore；
+ * 多个进程共享时可以基于 Redis（SET NX EX）或数据库唯一索引实现该接口
+ */
+type DedupStore interface {
+	// Reserve 占用幂等键：键不存在或已过期时占用并返回 reserved 为 true；
+	// 键已存在时返回 reserved 为 false，已完成时 record 为保存的发送结果，正在发送时 record 为 nil
+	Reserve(ctx context.Context, key string, ttl time.Duration) (record *DedupRecord, reserved bool, err error)
+	// Complete 保存发送结果，之后相同幂等键的请求直接返回该结果
+	Complete(ctx context.Context, key string, record DedupRecord, ttl time.Duration) error
+	// Release 释放幂等键，用于确认短信未发送的情况，之后相同幂等键的请求可以重新发送
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryDedupStore 内存中的幂等记录存储，只在当前进程内有效
+type MemoryDedupStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryDedupEntry
+	lastSweep time.Time
+}
+
+type memoryDedupEntry struct {
+	// 发送结果，正在发送时为 nil
+	record  *DedupRecord
+	expires time.Time
+}
+
+// 清理过期记录的最小间隔
+const memoryDedupSweepInterval = time.Minute
+
+// NewMemoryDedupStore 创建内存中的幂等记录存储，过期的记录会在之后的调用中自动清理
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{entries: make(map[string]memoryDedupEntry), lastSweep: time.Now()}
+}
+
+func (s *MemoryDedupStore) Reserve(_ context.Context, key string, ttl time.Duration) (*DedupRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+		return entry.record, false, nil
+	}
+	s.entries[key] = memoryDedupEntry{expires: now.Add(ttl)}
+	return nil, true, nil
+}
+
+func (s *MemoryDedupStore) Complete(_ context.Context, key string, record DedupRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryDedupEntry{record: &record, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryDedupStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// 清理过期记录，调用方需持有锁
+func (s *MemoryDedupStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryDedupSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// 按幂等键发送：相同幂等键已发送成功时直接返回保存的结果
+/**
+ * 调用前 ctx 已经结束时不占用幂等键；
+ * 确定短信没有发出（接口返回了发送失败的业务编码或 4xx 错误、超过本地限流、已熔断、请求发出前 ctx 已结束）时释放幂等键，允许重试；
+ * 其他错误（例如超时、网络错误、ErrAbandoned）时短信可能已经发出，保留占用直到过期，重试会返回 409 和 ErrSendInProgress
+ * 保存和释放不受 ctx 取消的影响，避免调用方超时后幂等记录丢失
+ */
+func (c *Client) sendOnce(ctx context.Context, key string, send func() (int32, third_party_tool_library.ResponseResult, error)) (int32, third_party_tool_library.ResponseResult, error) {
+	if key == "" || c.dedup == nil {
+		return send()
+	}
+	if err := ctx.Err(); err != nil {
+		return 500, third_party_tool_library.ResponseResult{}, contextError(err)
+	}
+	record, reserved, err := c.dedup.Reserve(ctx, key, c.dedupTTL)
+	if err != nil {
+		return 500, third_party_tool_library.ResponseResult{}, err
+	}
+	if !reserved {
+		if record == nil {
+			return 409, third_party_tool_library.ResponseResult{}, ErrSendInProgress
+		}
+		return record.StatusCode, record.result(), nil
+	}
+	statusCode, result, err := send()
+	switch {
+	case err != nil && notSent(err):
+		_ = c.dedup.Release(context.Background(), key)
+	case err != nil:
+		// 短信可能已经发出，保留占用
+	case tea.StringValue(result.Code) != "OK":
+		_ = c.dedup.Release(context.Background(), key)
+	default:
+		// 短信已发送，保存失败时仍返回发送结果，避免调用方误以为发送失败而重试
+		_ = c.dedup.Complete(context.Background(), key, newDedupRecord(statusCode, result), c.dedupTTL)
+	}
+	return statusCode, result, err
+}
+
+// 调用出错时短信是否确定没有发出：请求发出前 ctx 已结束、超过本地限流、已熔断，或接口以 4xx 拒绝了请求
+func notSent(err error) bool {
+	var canceled *canceledError
+	if errors.As(err, &canceled) || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var sdkErr *tea.SDKError
+	if errors.As(err, &sdkErr) {
+		statusCode := tea.IntValue(sdkErr.StatusCode)
+		return statusCode >= 400 && statusCode < 500
+	}
+	return false
+}
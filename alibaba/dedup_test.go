@@ -0,0 +1,162 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"third_party_tool_library"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// 使用内存幂等记录存储、不调用短信接口的客户端
+func newDedupClient(t *testing.T) *Client {
+	t.Helper()
+	client, err := NewClient("id", "secret", WithDedupStore(NewMemoryDedupStore(), time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// 记录调用次数的发送函数
+type fakeSend struct {
+	calls      int
+	statusCode int32
+	result     third_party_tool_library.ResponseResult
+	err        error
+}
+
+func (f *fakeSend) send() (int32, third_party_tool_library.ResponseResult, error) {
+	f.calls++
+	return f.statusCode, f.result, f.err
+}
+
+func TestSendOnceReplaysCompletedSend(t *testing.T) {
+	client := newDedupClient(t)
+	send := &fakeSend{statusCode: 200, result: okResult("biz-1")}
+
+	for i := 0; i < 3; i++ {
+		statusCode, result, err := client.sendOnce(context.Background(), "order-1", send.send)
+		if err != nil || statusCode != 200 || tea.StringValue(result.BizId) != "biz-1" {
+			t.Fatalf("sendOnce() #%d = %d, %+v, %v", i, statusCode, result, err)
+		}
+	}
+	if send.calls != 1 {
+		t.Fatalf("send called %d times, want 1", send.calls)
+	}
+	// 不同的幂等键互不影响
+	if _, _, err := client.sendOnce(context.Background(), "order-2", send.send); err != nil || send.calls != 2 {
+		t.Fatalf("sendOnce(order-2) = %v, calls %d", err, send.calls)
+	}
+}
+
+func TestSendOnceInProgress(t *testing.T) {
+	client := newDedupClient(t)
+	var statusCode int32
+	var err error
+	_, _, _ = client.sendOnce(context.Background(), "order-1", func() (int32, third_party_tool_library.ResponseResult, error) {
+		statusCode, _, err = client.sendOnce(context.Background(), "order-1", (&fakeSend{}).send)
+		return 200, okResult("biz-1"), nil
+	})
+	if statusCode != 409 || !errors.Is(err, ErrSendInProgress) {
+		t.Fatalf("concurrent sendOnce() = %d, %v, want 409 ErrSendInProgress", statusCode, err)
+	}
+}
+
+func TestSendOnceReleasesWhenNotSent(t *testing.T) {
+	tests := []struct {
+		name string
+		send *fakeSend
+	}{
+		{"business error", &fakeSend{statusCode: 200, result: third_party_tool_library.ResponseResult{Code: tea.String("isv.BUSINESS_LIMIT_CONTROL")}}},
+		{"rate limited", &fakeSend{statusCode: 429, err: ErrRateLimited}},
+		{"circuit open", &fakeSend{statusCode: 503, err: ErrCircuitOpen}},
+		{"canceled before call", &fakeSend{statusCode: 500, err: contextError(context.Canceled)}},
+		{"client error", &fakeSend{statusCode: 400, err: &tea.SDKError{StatusCode: tea.Int(400), Code: tea.String("InvalidParameter")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newDedupClient(t)
+			if _, _, _ = client.sendOnce(context.Background(), "order-1", tt.send.send); tt.send.calls != 1 {
+				t.Fatalf("send called %d times", tt.send.calls)
+			}
+			// 幂等键已释放，可以重新发送
+			retry := &fakeSend{statusCode: 200, result: okResult("biz-2")}
+			statusCode, result, err := client.sendOnce(context.Background(), "order-1", retry.send)
+			if err != nil || retry.calls != 1 || tea.StringValue(result.BizId) != "biz-2" {
+				t.Fatalf("retry sendOnce() = %d, %+v, %v, calls %d", statusCode, result, err, retry.calls)
+			}
+		})
+	}
+}
+
+func TestSendOnceKeepsReservationWhenOutcomeUnknown(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"network error", errors.New("read tcp: connection reset by peer")},
+		{"server error", &tea.SDKError{StatusCode: tea.Int(502), Code: tea.String("BadGateway")}},
+		{"abandoned", &abandonedError{err: context.DeadlineExceeded}},
+		{"wrapped abandoned", fmt.Errorf("发送失败：%w", &abandonedError{err: context.Canceled})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newDedupClient(t)
+			send := &fakeSend{statusCode: 500, err: tt.err}
+			if _, _, err := client.sendOnce(context.Background(), "order-1", send.send); !errors.Is(err, tt.err) {
+				t.Fatalf("sendOnce() error = %v, want %v", err, tt.err)
+			}
+			// 短信可能已经发出，重试返回 409 且不再调用短信接口
+			retry := &fakeSend{statusCode: 200, result: okResult("biz-2")}
+			statusCode, _, err := client.sendOnce(context.Background(), "order-1", retry.send)
+			if statusCode != 409 || !errors.Is(err, ErrSendInProgress) || retry.calls != 0 {
+				t.Fatalf("retry sendOnce() = %d, %v, calls %d, want 409 ErrSendInProgress", statusCode, err, retry.calls)
+			}
+		})
+	}
+}
+
+func TestSendOnceCanceledBeforeReserve(t *testing.T) {
+	client := newDedupClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	send := &fakeSend{statusCode: 200, result: okResult("biz-1")}
+	if _, _, err := client.sendOnce(ctx, "order-1", send.send); !errors.Is(err, context.Canceled) || send.calls != 0 {
+		t.Fatalf("sendOnce() = %v, calls %d, want context.Canceled without sending", err, send.calls)
+	}
+	// 没有占用幂等键
+	if _, _, err := client.sendOnce(context.Background(), "order-1", send.send); err != nil || send.calls != 1 {
+		t.Fatalf("sendOnce() after cancel = %v, calls %d", err, send.calls)
+	}
+}
+
+func TestMemoryDedupStoreExpires(t *testing.T) {
+	store := NewMemoryDedupStore()
+	ctx := context.Background()
+	if _, reserved, _ := store.Reserve(ctx, "order-1", time.Millisecond); !reserved {
+		t.Fatal("first Reserve() was not reserved")
+	}
+	if _, reserved, _ := store.Reserve(ctx, "order-1", time.Minute); reserved {
+		t.Fatal("second Reserve() reserved an in-progress key")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, reserved, _ := store.Reserve(ctx, "order-1", time.Minute); !reserved {
+		t.Fatal("Reserve() after expiry was not reserved")
+	}
+	if err := store.Complete(ctx, "order-1", DedupRecord{StatusCode: 200, Code: "OK", BizId: "biz-1"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	record, reserved, _ := store.Reserve(ctx, "order-1", time.Minute)
+	if reserved || record == nil || record.BizId != "biz-1" {
+		t.Fatalf("Reserve() after Complete() = %+v, %v", record, reserved)
+	}
+}
+
+func okResult(bizId string) third_party_tool_library.ResponseResult {
+	return third_party_tool_library.ResponseResult{Code: tea.String("OK"), Message: tea.String("OK"), BizId: tea.String(bizId)}
+}
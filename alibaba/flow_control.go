@@ -0,0 +1,350 @@
+package alibaba
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited 超过本地限流，短信未发送
+var ErrRateLimited = errors.New("短信发送超过限流")
+
+// TemplateType 短信模板类型，与 AddSmsTemplate 的 templateType 一致
+type TemplateType int32
+
+const (
+	// TemplateTypeVerification 验证码
+	TemplateTypeVerification TemplateType = 0
+	// TemplateTypeNotification 短信通知
+	TemplateTypeNotification TemplateType = 1
+	// TemplateTypeMarketing 推广短信
+	TemplateTypeMarketing TemplateType = 2
+	// TemplateTypeGlobe 国际/港澳台消息
+	TemplateTypeGlobe TemplateType = 3
+)
+
+func (t TemplateType) String() string {
+	switch t {
+	case TemplateTypeVerification:
+		return "verification"
+	case TemplateTypeNotification:
+		return "notification"
+	case TemplateTypeMarketing:
+		return "marketing"
+	case TemplateTypeGlobe:
+		return "globe"
+	default:
+		return fmt.Sprintf("TemplateType(%d)", int32(t))
+	}
+}
+
+// 解析 TemplateType.String 的结果
+func parseTemplateType(name string) (TemplateType, error) {
+	for _, templateType := range []TemplateType{TemplateTypeVerification, TemplateTypeNotification, TemplateTypeMarketing, TemplateTypeGlobe} {
+		if strings.EqualFold(strings.TrimSpace(name), templateType.String()) {
+			return templateType, nil
+		}
+	}
+	return 0, fmt.Errorf("模板类型 %s 不合法，应为 verification、notification、marketing 或 globe", name)
+}
+
+// RateLimit 限流规则：每 Period 最多发送 Limit 条
+/**
+ * 使用令牌桶实现：桶的容量为 Limit，每 Period 补满，
+ * 例如每天 10 条的规则允许一次发送 10 条，之后每 2.4 小时恢复 1 条
+ */
+type RateLimit struct {
+	// 周期内最多发送的条数
+	Limit int
+	// 周期
+	Period time.Duration
+}
+
+func (l RateLimit) String() string {
+	return fmt.Sprintf("每 %s 最多 %d 条", l.Period, l.Limit)
+}
+
+// FlowRules 一类短信模板的限流规则
+type FlowRules struct {
+	// 同一号码的限流，例如每分钟 1 条、每小时 5 条、每天 10 条，不同模板类型分别计数
+	PerPhone []RateLimit
+	// 同一模板的限流，按发送的号码个数计数
+	PerTemplate []RateLimit
+}
+
+// DefaultVerificationRules 阿里云对验证码短信的默认号码限流：同一号码每分钟 1 条、每小时 5 条、每天 10 条
+func DefaultVerificationRules() FlowRules {
+	return FlowRules{PerPhone: []RateLimit{
+		{Limit: 1, Period: time.Minute},
+		{Limit: 5, Period: time.Hour},
+		{Limit: 10, Period: 24 * time.Hour},
+	}}
+}
+
+// FlowControl 本地限流配置
+/**
+ * 在调用发送接口之前按全局、模板和号码三个维度限流，避免发送后才收到 isv.BUSINESS_LIMIT_CONTROL 等流控错误，例如：
+ *	alibaba.NewFlowLimiter(alibaba.FlowControl{
+ *		Global:        []alibaba.RateLimit{{Limit: 100, Period: time.Second}},
+ *		Rules:         map[alibaba.TemplateType]alibaba.FlowRules{alibaba.TemplateTypeVerification: alibaba.DefaultVerificationRules()},
+ *		TemplateTypes: map[string]alibaba.TemplateType{"SMS_153055065": alibaba.TemplateTypeVerification},
+ *	})
+ */
+type FlowControl struct {
+	// 全局限流，对应账号的接口 QPS，按调用次数计数（批量发送每个分片计一次）
+	Global []RateLimit
+	// 各模板类型的限流规则
+	Rules map[TemplateType]FlowRules
+	// 未登记类型的模板使用的限流规则
+	Default FlowRules
+	// 模板 Code 对应的模板类型，未登记的模板使用 Default
+	TemplateTypes map[string]TemplateType
+}
+
+// FlowScope 限流的维度
+type FlowScope string
+
+const (
+	// FlowScopeGlobal 全局限流
+	FlowScopeGlobal FlowScope = "global"
+	// FlowScopeTemplate 同一模板的限流
+	FlowScopeTemplate FlowScope = "template"
+	// FlowScopePhone 同一号码的限流
+	FlowScopePhone FlowScope = "phone"
+)
+
+// RateLimitError 超过本地限流，短信未发送
+type RateLimitError struct {
+	// 超过限流的维度
+	Scope FlowScope
+	// 超过限流的模板 Code 或手机号码，全局限流时为空
+	Key string
+	// 超过的限流规则
+	Limit RateLimit
+	// 需要等待的时间，之后重试可以通过该规则；为 0 表示单次发送的条数超过了规则的上限，重试也不会成功
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	var b strings.Builder
+	b.WriteString("短信发送超过限流（")
+	switch e.Scope {
+	case FlowScopeTemplate:
+		b.WriteString("模板 ")
+		b.WriteString(e.Key)
+		b.WriteString("，")
+	case FlowScopePhone:
+		b.WriteString("号码 ")
+		b.WriteString(e.Key)
+		b.WriteString("，")
+	}
+	b.WriteString(e.Limit.String())
+	b.WriteString("）")
+	if e.RetryAfter > 0 {
+		fmt.Fprintf(&b, "，请在 %s 后重试", e.RetryAfter.Round(time.Millisecond))
+	} else {
+		b.WriteString("，单次发送的条数超过了上限")
+	}
+	return b.String()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// FlowLimiter 本地限流器，可以在多个客户端之间共享
+type FlowLimiter struct {
+	global        []RateLimit
+	rules         map[TemplateType]FlowRules
+	defaultRules  FlowRules
+	templateTypes map[string]TemplateType
+
+	// 保护 buckets，各条规则的令牌桶只在持有该锁时访问
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// 清理已补满令牌的桶的最小间隔
+const flowBucketSweepInterval = time.Minute
+
+// NewFlowLimiter
+/** 创建本地限流器
+ * @param config 限流配置
+ * @return *FlowLimiter 限流器，通过 WithFlowControl 用于客户端
+ * @return error 限流规则的条数或周期小于等于 0 时返回错误
+ */
+func NewFlowLimiter(config FlowControl) (*FlowLimiter, error) {
+	if err := validateRateLimits("全局", config.Global); err != nil {
+		return nil, err
+	}
+	if err := validateFlowRules("默认", config.Default); err != nil {
+		return nil, err
+	}
+	rules := make(map[TemplateType]FlowRules, len(config.Rules))
+	for templateType, rule := range config.Rules {
+		if err := validateFlowRules(templateType.String(), rule); err != nil {
+			return nil, err
+		}
+		rules[templateType] = rule
+	}
+	templateTypes := make(map[string]TemplateType, len(config.TemplateTypes))
+	for templateCode, templateType := range config.TemplateTypes {
+		templateTypes[strings.TrimSpace(templateCode)] = templateType
+	}
+	return &FlowLimiter{
+		global:        append([]RateLimit(nil), config.Global...),
+		rules:         rules,
+		defaultRules:  config.Default,
+		templateTypes: templateTypes,
+		buckets:       make(map[string]*tokenBucket),
+		lastSweep:     time.Now(),
+	}, nil
+}
+
+func validateFlowRules(name string, rules FlowRules) error {
+	if err := validateRateLimits(name+"号码", rules.PerPhone); err != nil {
+		return err
+	}
+	return validateRateLimits(name+"模板", rules.PerTemplate)
+}
+
+func validateRateLimits(name string, limits []RateLimit) error {
+	for _, limit := range limits {
+		if limit.Limit <= 0 || limit.Period <= 0 {
+			return fmt.Errorf("%s限流规则不合法：条数和周期必须大于 0", name)
+		}
+	}
+	return nil
+}
+
+// 一次限流检查
+type flowCheck struct {
+	key   string
+	scope FlowScope
+	// 模板 Code 或手机号码
+	subject string
+	limit   RateLimit
+	count   int
+}
+
+// Allow
+/** 检查并占用限流额度：所有规则都通过时扣减额度并返回 nil，任一规则不通过时不扣减任何额度
+ * @param templateCode 短信模板 Code，按 FlowControl.TemplateTypes 确定限流规则
+ * @param phoneNumbers 接收短信的手机号码
+ * @return error 超过限流时返回 *RateLimitError，有多条规则不通过时返回需要等待最久的一条
+ */
+func (l *FlowLimiter) Allow(templateCode string, phoneNumbers ...string) error {
+	templateCode = strings.TrimSpace(templateCode)
+	templateType, ok := l.templateTypes[templateCode]
+	if !ok {
+		return l.allow("default", l.defaultRules, templateCode, phoneNumbers)
+	}
+	return l.AllowType(templateType, templateCode, phoneNumbers...)
+}
+
+// AllowType
+/** 按指定的模板类型检查并占用限流额度，用于没有模板 Code 的短信，例如直接发送内容的国际短信
+ * 参数与返回值同 Allow
+ */
+func (l *FlowLimiter) AllowType(templateType TemplateType, templateCode string, phoneNumbers ...string) error {
+	rules, ok := l.rules[templateType]
+	if !ok {
+		rules = l.defaultRules
+	}
+	return l.allow(templateType.String(), rules, strings.TrimSpace(templateCode), phoneNumbers)
+}
+
+func (l *FlowLimiter) allow(category string, rules FlowRules, templateCode string, phoneNumbers []string) error {
+	var checks []flowCheck
+	for i, limit := range l.global {
+		checks = append(checks, flowCheck{key: fmt.Sprintf("global#%d", i), scope: FlowScopeGlobal, limit: limit, count: 1})
+	}
+	if templateCode != "" {
+		for i, limit := range rules.PerTemplate {
+			checks = append(checks, flowCheck{key: fmt.Sprintf("template#%s#%d", templateCode, i), scope: FlowScopeTemplate, subject: templateCode, limit: limit, count: len(phoneNumbers)})
+		}
+	}
+	if len(rules.PerPhone) > 0 {
+		counts := make(map[string]int, len(phoneNumbers))
+		var phones []string
+		for _, phoneNumber := range phoneNumbers {
+			if counts[phoneNumber] == 0 {
+				phones = append(phones, phoneNumber)
+			}
+			counts[phoneNumber]++
+		}
+		for _, phoneNumber := range phones {
+			for i, limit := range rules.PerPhone {
+				checks = append(checks, flowCheck{key: fmt.Sprintf("phone#%s#%s#%d", category, phoneNumber, i), scope: FlowScopePhone, subject: phoneNumber, limit: limit, count: counts[phoneNumber]})
+			}
+		}
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.sweep(now)
+	var limited *RateLimitError
+	for _, check := range checks {
+		bucket := l.bucket(check, now)
+		if bucket.available(now) >= float64(check.count) {
+			continue
+		}
+		var retryAfter time.Duration
+		if check.count <= check.limit.Limit {
+			retryAfter = bucket.delay(now, float64(check.count))
+		}
+		if limited == nil || (limited.RetryAfter > 0 && (retryAfter == 0 || retryAfter > limited.RetryAfter)) {
+			limited = &RateLimitError{Scope: check.scope, Key: check.subject, Limit: check.limit, RetryAfter: retryAfter}
+		}
+	}
+	if limited != nil {
+		return limited
+	}
+	for _, check := range checks {
+		l.buckets[check.key].take(now, float64(check.count))
+	}
+	return nil
+}
+
+// 获取规则对应的令牌桶，不存在时创建一个满的桶，调用方需持有锁
+func (l *FlowLimiter) bucket(check flowCheck, now time.Time) *tokenBucket {
+	bucket, ok := l.buckets[check.key]
+	if !ok {
+		bucket = newTokenBucket(float64(check.limit.Limit)/check.limit.Period.Seconds(), check.limit.Limit)
+		bucket.last = now
+		l.buckets[check.key] = bucket
+	}
+	return bucket
+}
+
+// 清理已补满的令牌桶，补满的桶与新建的桶等价，调用方需持有锁
+func (l *FlowLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < flowBucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if bucket.available(now) >= bucket.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// 按本地限流检查已解析的请求，未配置 WithFlowControl 时不限流
+func (c *Client) allowFlow(resolved *resolvedSendRequest) error {
+	if c.flow == nil {
+		return nil
+	}
+	phoneNumbers := make([]string, len(resolved.Recipients))
+	for i, recipient := range resolved.Recipients {
+		phoneNumbers[i] = recipient.PhoneNumber
+	}
+	return c.flow.Allow(resolved.TemplateCode, phoneNumbers...)
+}
@@ -0,0 +1,54 @@
+package alibaba
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFlowLimiterAllowAllOrNothing(t *testing.T) {
+	limiter, err := NewFlowLimiter(FlowControl{
+		Default: FlowRules{
+			PerPhone:    []RateLimit{{Limit: 2, Period: time.Hour}},
+			PerTemplate: []RateLimit{{Limit: 3, Period: time.Hour}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = limiter.Allow("SMS_1", "13800000001", "13800000002"); err != nil {
+		t.Fatal(err)
+	}
+	// 模板额度只剩 1 条，两条都不扣减
+	var limited *RateLimitError
+	if err = limiter.Allow("SMS_1", "13800000001", "13800000003"); !errors.As(err, &limited) || limited.Scope != FlowScopeTemplate {
+		t.Fatalf("Allow() = %v, want template limit", err)
+	}
+	if limited.RetryAfter <= 0 || limited.RetryAfter > 20*time.Minute {
+		t.Fatalf("RetryAfter = %v, want about 20m", limited.RetryAfter)
+	}
+	if err = limiter.Allow("SMS_1", "13800000001"); err != nil {
+		t.Fatalf("Allow() after rejected request = %v", err)
+	}
+	// 同一号码超过上限，重试也不会成功
+	if err = limiter.Allow("SMS_2", "13800000004", "13800000004", "13800000004"); !errors.As(err, &limited) || limited.Scope != FlowScopePhone || limited.RetryAfter != 0 {
+		t.Fatalf("Allow() = %v, want phone limit without RetryAfter", err)
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	bucket := newTokenBucket(100, 2)
+	for i := 0; i < 4; i++ {
+		if delay := bucket.reserve(); i < 2 && delay != 0 {
+			t.Fatalf("reserve() #%d = %v within burst", i, delay)
+		} else if i >= 2 && (delay <= 0 || delay > 25*time.Millisecond) {
+			t.Fatalf("reserve() #%d = %v, want up to 20ms", i, delay)
+		}
+	}
+	// 归还的令牌可以被之后的调用使用，只需等待 1 个令牌
+	bucket.cancel()
+	bucket.cancel()
+	if delay := bucket.reserve(); delay > 10*time.Millisecond {
+		t.Fatalf("reserve() after cancel = %v", delay)
+	}
+}
@@ -0,0 +1,94 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// ErrAbandoned ctx 被取消或超过截止时间时请求已经发出，不再等待结果；请求可能仍会在服务端完成，例如短信已经发出
+var ErrAbandoned = errors.New("请求已发出，未等待调用结果")
+
+// invoke 在 ctx 的控制下执行一次 SDK 调用
+/**
+ * 底层 SDK 不支持 context，无法中止已经发出的 http 请求，这里做三件事：
+ * 1. 调用前 ctx 已经结束时不发出请求，返回包装后的 ctx.Err()；
+ * 2. ctx 带有截止时间时，将本次调用的请求超时缩短到截止时间之内（按秒向上取整，避免 SDK 为每个不同的超时值都缓存一个 http 客户端），
+ *    超时后底层 http 请求会被中止；
+ * 3. ctx 被取消时立即返回 *abandonedError，底层请求在后台继续执行直到请求超时，其结果会被丢弃，
+ *    此时请求可能已经完成（例如短信已经发出），调用方不应直接重试，需要时使用幂等键（见 WithDedupStore）。
+ * 返回的错误可以使用 errors.Is(err, context.Canceled) 或 errors.Is(err, context.DeadlineExceeded) 判断，
+ * 请求已经发出时 errors.Is(err, ErrAbandoned) 为 true
+ */
+func invoke[T any](ctx context.Context, runtime *util.RuntimeOptions, call func(runtime *util.RuntimeOptions) (T, error)) (T, error) {
+	var zero T
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, contextError(err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := readTimeoutValue((time.Until(deadline) + time.Second - 1).Truncate(time.Second))
+		if runtime.ReadTimeout == nil || tea.IntValue(runtime.ReadTimeout) <= 0 || tea.IntValue(runtime.ReadTimeout) > remaining {
+			runtime.ReadTimeout = tea.Int(remaining)
+		}
+	}
+	if ctx.Done() == nil {
+		return call(runtime)
+	}
+
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := call(runtime)
+		done <- outcome{value, err}
+	}()
+	select {
+	case o := <-done:
+		return o.value, o.err
+	case <-ctx.Done():
+		return zero, &abandonedError{err: ctx.Err()}
+	}
+}
+
+// 请求发出前 ctx 已经结束时返回的错误
+func contextError(err error) error {
+	return &canceledError{err: err}
+}
+
+// canceledError 请求发出前 ctx 被取消或超过截止时间，请求没有发出
+type canceledError struct {
+	err error
+}
+
+func (e *canceledError) Error() string {
+	return "短信接口调用未完成：" + e.err.Error()
+}
+
+func (e *canceledError) Unwrap() error {
+	return e.err
+}
+
+// abandonedError 请求已经发出后 ctx 被取消或超过截止时间
+type abandonedError struct {
+	err error
+}
+
+func (e *abandonedError) Error() string {
+	return "短信接口请求已发出，未等待调用结果：" + e.err.Error()
+}
+
+func (e *abandonedError) Unwrap() error {
+	return e.err
+}
+
+func (e *abandonedError) Is(target error) bool {
+	return target == ErrAbandoned
+}
@@ -0,0 +1,57 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+)
+
+func TestInvokeCanceledBeforeCall(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	_, err := invoke(ctx, &util.RuntimeOptions{}, func(*util.RuntimeOptions) (int, error) {
+		called = true
+		return 0, nil
+	})
+	if called {
+		t.Fatal("call was made with a canceled context")
+	}
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrAbandoned) || !notSent(err) {
+		t.Fatalf("invoke() error = %v, want canceled before the call", err)
+	}
+}
+
+func TestInvokeCanceledDuringCall(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	_, err := invoke(ctx, &util.RuntimeOptions{}, func(*util.RuntimeOptions) (int, error) {
+		cancel()
+		<-release
+		return 1, nil
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, ErrAbandoned) || notSent(err) {
+		t.Fatalf("invoke() error = %v, want an abandoned call", err)
+	}
+}
+
+func TestInvokeShortensReadTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	runtime := &util.RuntimeOptions{}
+	var readTimeout int
+	_, err := invoke(ctx, runtime, func(runtime *util.RuntimeOptions) (int, error) {
+		readTimeout = *runtime.ReadTimeout
+		return 0, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readTimeout <= 0 || readTimeout > 2000 {
+		t.Fatalf("ReadTimeout = %dms, want within the 2s deadline", readTimeout)
+	}
+}
@@ -0,0 +1,68 @@
+package alibaba
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// 本地模拟的短信接口，handler 根据请求参数返回响应体
+func newSmsServer(t *testing.T, handler func(params url.Values) map[string]string) *httptest.Server {
+	t.Helper()
+	return newApiServer(t, func(params url.Values) interface{} { return handler(params) })
+}
+
+// 本地模拟的短信接口，响应体可以包含嵌套的对象和数组
+func newApiServer(t *testing.T, handler func(params url.Values) interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(handler(r.Form)); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// 使用模拟接口创建客户端
+func newTestClient(t *testing.T, server *httptest.Server, opts ...Option) *Client {
+	t.Helper()
+	opts = append([]Option{WithEndpoint(server.URL), WithSignName("测试签名")}, opts...)
+	client, err := NewClient("id", "secret", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func okResponse(bizId string) map[string]string {
+	return map[string]string{"Code": "OK", "Message": "OK", "RequestId": "request-" + bizId, "BizId": bizId}
+}
+
+// 本地模拟的国际短信接口，记录请求的接口名称和参数
+type globeRequest struct {
+	action string
+	params url.Values
+}
+
+func newGlobeServer(t *testing.T, body map[string]interface{}, requests *[]globeRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		*requests = append(*requests, globeRequest{action: r.Header.Get("x-acs-action"), params: r.Form})
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
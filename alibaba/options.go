@@ -0,0 +1,270 @@
+package alibaba
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+const (
+	// DefaultRegionId 默认地域
+	DefaultRegionId = "cn-hangzhou"
+	// DefaultProtocol 默认请求协议
+	DefaultProtocol = "https"
+	// DefaultConnectTimeout 默认建立连接超时时间
+	DefaultConnectTimeout = 5 * time.Second
+	// DefaultReadTimeout 默认请求超时时间
+	DefaultReadTimeout = 10 * time.Second
+)
+
+// 支持的网络类型，public 表示公网
+var supportedNetworks = map[string]bool{
+	"public":    true,
+	"vpc":       true,
+	"intranet":  true,
+	"share":     true,
+	"ipv6":      true,
+	"proxy":     true,
+	"inner":     true,
+	"dualstack": true,
+}
+
+var regionIdPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z0-9]+)+$`)
+
+// 客户端配置项
+type clientOptions struct {
+	endpoint string
+	regionId string
+	protocol string
+	network  string
+
+	connectTimeout time.Duration
+	readTimeout    time.Duration
+	httpProxy      string
+	httpsProxy     string
+	noProxy        string
+	ca             string
+	maxIdleConns   int
+	keepAlive      bool
+	maxAttempts    int
+	backoffPeriod  time.Duration
+
+	signName  string
+	templates map[string]Template
+	qps       float64
+	burst     int
+
+	validateParams   bool
+	templateCacheTTL time.Duration
+
+	globeEndpoint string
+	senderId      string
+
+	dedup    DedupStore
+	dedupTTL time.Duration
+	retry    *retryPolicy
+	flow     *FlowLimiter
+	breaker  *CircuitBreaker
+
+	globeBreaker *CircuitBreaker
+}
+
+func defaultClientOptions() *clientOptions {
+	return &clientOptions{
+		regionId:       DefaultRegionId,
+		protocol:       DefaultProtocol,
+		network:        "public",
+		connectTimeout: DefaultConnectTimeout,
+		readTimeout:    DefaultReadTimeout,
+		keepAlive:      true,
+	}
+}
+
+// Option 客户端配置项
+/**
+ * 创建客户端时传入，例如：
+ *	alibaba.NewClient(ak, sk, alibaba.WithRegionId("ap-southeast-1"))
+ *	alibaba.NewClient(ak, sk, alibaba.WithEndpoint("http://127.0.0.1:8080"))
+ * 配置项不合法时，创建客户端会返回错误
+ */
+type Option func(*clientOptions) error
+
+// WithEndpoint
+/** 指定服务地址
+ * 未指定时根据地域和网络类型自动选择，例如 cn-hangzhou 公网为 dysmsapi.aliyuncs.com，ap-southeast-1 为 dysmsapi.ap-southeast-1.aliyuncs.com
+ * @param endpoint 服务地址，可以是域名（dysmsapi.aliyuncs.com）、域名加端口（127.0.0.1:8080），
+ *                 也可以带协议（http://127.0.0.1:8080），带协议时同时设置请求协议
+ */
+func WithEndpoint(endpoint string) Option {
+	return func(o *clientOptions) (err error) {
+		o.endpoint, err = o.parseEndpoint(endpoint)
+		return err
+	}
+}
+
+// 解析服务地址，带协议时同时设置请求协议
+func (o *clientOptions) parseEndpoint(endpoint string) (string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", fmt.Errorf("服务地址 %s 不合法：%w", endpoint, err)
+		}
+		if u.Path != "" && u.Path != "/" || u.RawQuery != "" {
+			return "", fmt.Errorf("服务地址 %s 不能包含路径或参数", endpoint)
+		}
+		if err = WithProtocol(u.Scheme)(o); err != nil {
+			return "", err
+		}
+		endpoint = u.Host
+	}
+	if err := validateEndpointHost(endpoint); err != nil {
+		return "", err
+	}
+	return endpoint, nil
+}
+
+// WithGlobeEndpoint
+/** 指定国际/港澳台短信（SendGlobe）的服务地址，默认 DefaultGlobeEndpoint
+ * @param endpoint 服务地址，格式同 WithEndpoint
+ */
+func WithGlobeEndpoint(endpoint string) Option {
+	return func(o *clientOptions) (err error) {
+		o.globeEndpoint, err = o.parseEndpoint(endpoint)
+		return err
+	}
+}
+
+// WithRegionId
+/** 指定地域，例如 cn-hangzhou、ap-southeast-1，默认 cn-hangzhou
+ * @param regionId 地域ID
+ */
+func WithRegionId(regionId string) Option {
+	return func(o *clientOptions) error {
+		regionId = strings.TrimSpace(regionId)
+		if !regionIdPattern.MatchString(regionId) {
+			return fmt.Errorf("地域ID %s 不合法", regionId)
+		}
+		o.regionId = regionId
+		return nil
+	}
+}
+
+// WithProtocol
+/** 指定请求协议，默认 https
+ * @param protocol http 或 https
+ */
+func WithProtocol(protocol string) Option {
+	return func(o *clientOptions) error {
+		protocol = strings.ToLower(strings.TrimSpace(protocol))
+		if protocol != "http" && protocol != "https" {
+			return fmt.Errorf("请求协议 %s 不合法，仅支持 http 和 https", protocol)
+		}
+		o.protocol = protocol
+		return nil
+	}
+}
+
+// WithNetwork
+/** 指定网络类型，默认 public（公网）
+ * 未指定服务地址时，服务地址会根据网络类型生成，例如 vpc 为 dysmsapi-vpc.aliyuncs.com
+ * @param network 网络类型：public、vpc、intranet、share、ipv6、proxy、inner、dualstack
+ */
+func WithNetwork(network string) Option {
+	return func(o *clientOptions) error {
+		network = strings.ToLower(strings.TrimSpace(network))
+		if !supportedNetworks[network] {
+			return fmt.Errorf("网络类型 %s 不合法", network)
+		}
+		o.network = network
+		return nil
+	}
+}
+
+func validateEndpointHost(endpoint string) error {
+	if endpoint == "" {
+		return fmt.Errorf("服务地址不能为空")
+	}
+	host := endpoint
+	if h, port, err := net.SplitHostPort(endpoint); err == nil {
+		if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+			return fmt.Errorf("服务地址 %s 的端口不合法", endpoint)
+		}
+		host = h
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@ ") {
+		return fmt.Errorf("服务地址 %s 不合法", endpoint)
+	}
+	return nil
+}
+
+func newClientOptions(opts []Option) (*clientOptions, error) {
+	o := defaultClientOptions()
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// 根据配置项生成 SDK 配置
+func (o *clientOptions) config() *openapi.Config {
+	config := &openapi.Config{
+		RegionId: tea.String(o.regionId),
+		Protocol: tea.String(o.protocol),
+	}
+	// Endpoint 请参考 https://api.aliyun.com/product/Dysmsapi
+	if o.endpoint != "" {
+		config.Endpoint = tea.String(o.endpoint)
+	}
+	if o.network != "public" {
+		config.Network = tea.String(o.network)
+	}
+	config.ConnectTimeout = tea.Int(connectTimeoutValue(o.connectTimeout))
+	config.ReadTimeout = tea.Int(readTimeoutValue(o.readTimeout))
+	if o.httpProxy != "" {
+		config.HttpProxy = tea.String(o.httpProxy)
+	}
+	if o.httpsProxy != "" {
+		config.HttpsProxy = tea.String(o.httpsProxy)
+	}
+	if o.noProxy != "" {
+		config.NoProxy = tea.String(o.noProxy)
+	}
+	if o.ca != "" {
+		config.Ca = tea.String(o.ca)
+	}
+	if o.maxIdleConns > 0 {
+		config.MaxIdleConns = tea.Int(o.maxIdleConns)
+	}
+	if !o.keepAlive {
+		// 请求头中带上 Connection: close，每次请求结束后关闭连接
+		config.GlobalParameters = &openapi.GlobalParameters{
+			Headers: map[string]*string{"Connection": tea.String("close")},
+		}
+	}
+	return config
+}
+
+// 根据配置项生成每次调用的默认运行时参数（自动重试、退避策略）
+func (o *clientOptions) runtime() util.RuntimeOptions {
+	runtime := util.RuntimeOptions{}
+	if o.maxAttempts > 1 {
+		runtime.Autoretry = tea.Bool(true)
+		runtime.MaxAttempts = tea.Int(o.maxAttempts)
+		if o.backoffPeriod > 0 {
+			runtime.BackoffPolicy = tea.String("exponential")
+			runtime.BackoffPeriod = tea.Int(backoffPeriodValue(o.backoffPeriod))
+		}
+	}
+	return runtime
+}
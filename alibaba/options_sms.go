@@ -0,0 +1,177 @@
+package alibaba
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Template 命名的短信模板
+type Template struct {
+	// 短信模板 Code，例如 SMS_153055065
+	Code string
+	// 使用该模板时的短信签名，为空时使用客户端的默认签名
+	SignName string
+}
+
+// WithSignName
+/** 指定默认短信签名，发送时未指定签名则使用该签名
+ * @param signName 短信签名名称
+ */
+func WithSignName(signName string) Option {
+	return func(o *clientOptions) error {
+		signName = strings.TrimSpace(signName)
+		if signName == "" {
+			return fmt.Errorf("默认短信签名不能为空")
+		}
+		o.signName = signName
+		return nil
+	}
+}
+
+// WithTemplate
+/** 注册命名的短信模板，之后可以通过 SendTemplate 按名称发送，避免在业务代码中硬编码模板 Code
+ * @param name 模板名称，例如 login、order_shipped
+ * @param template 模板 Code 与签名
+ */
+func WithTemplate(name string, template Template) Option {
+	return func(o *clientOptions) error {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("模板名称不能为空")
+		}
+		if strings.TrimSpace(template.Code) == "" {
+			return fmt.Errorf("模板 %s 的模板 Code 不能为空", name)
+		}
+		if o.templates == nil {
+			o.templates = make(map[string]Template)
+		}
+		o.templates[name] = template
+		return nil
+	}
+}
+
+// WithSenderId
+/** 指定国际/港澳台短信默认的发送方 ID（From），发送时未指定则使用该 ID
+ * @param senderId 发送方 ID，见 GlobeSendRequest.From
+ */
+func WithSenderId(senderId string) Option {
+	return func(o *clientOptions) error {
+		senderId = strings.TrimSpace(senderId)
+		if err := validateSenderId(senderId); err != nil {
+			return err
+		}
+		o.senderId = senderId
+		return nil
+	}
+}
+
+// WithRateLimit
+/** 限制短信发送的速率，超过速率的发送会等待，直到拿到令牌或 ctx 被取消
+ * @param qps 每秒最多发送的次数，为 0 表示不限制
+ * @param burst 允许的突发次数，小于 1 时按 1 处理
+ */
+func WithRateLimit(qps float64, burst int) Option {
+	return func(o *clientOptions) error {
+		if qps < 0 {
+			return fmt.Errorf("发送速率不能小于 0")
+		}
+		o.qps = qps
+		o.burst = burst
+		return nil
+	}
+}
+
+// WithFlowControl
+/** 发送前按全局、模板和号码限流，超过限流时不发送，返回 429 和 *RateLimitError；
+ * 与 WithRateLimit 不同，超过限流时立即返回而不是等待，适合在本地模拟阿里云的流控规则
+ * @param limiter 限流器，见 NewFlowLimiter，多个客户端共享同一个限流器时共同计数
+ */
+func WithFlowControl(limiter *FlowLimiter) Option {
+	return func(o *clientOptions) error {
+		if limiter == nil {
+			return fmt.Errorf("限流器不能为空")
+		}
+		o.flow = limiter
+		return nil
+	}
+}
+
+// WithCircuitBreaker
+/** 在熔断器的保护下调用发送接口，短信接口连续失败时熔断，熔断期间直接返回 503 和 ErrCircuitOpen，
+ * 熔断时间过后允许少量试探请求，成功后恢复；当前状态见 Client.CircuitState 和 CircuitBreaker.Stats
+ * 该熔断器只用于国内短信，未配置 WithGlobeCircuitBreaker 时国际/港澳台短信使用一个配置相同、单独计数的熔断器
+ * @param breaker 熔断器，见 NewCircuitBreaker，多个客户端共享同一个熔断器时共同计数
+ */
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(o *clientOptions) error {
+		if breaker == nil {
+			return fmt.Errorf("熔断器不能为空")
+		}
+		o.breaker = breaker
+		return nil
+	}
+}
+
+// WithGlobeCircuitBreaker
+/** 国际/港澳台短信（SendGlobe）使用的熔断器，与国内短信的熔断器分别计数，当前状态见 Client.GlobeCircuitState
+ * @param breaker 熔断器，见 NewCircuitBreaker，不要与 WithCircuitBreaker 使用同一个熔断器
+ */
+func WithGlobeCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(o *clientOptions) error {
+		if breaker == nil {
+			return fmt.Errorf("熔断器不能为空")
+		}
+		o.globeBreaker = breaker
+		return nil
+	}
+}
+
+// WithTemplateValidation
+/** 发送前校验模板参数：通过 QuerySmsTemplate 查询模板内容并缓存，提取 ${...} 变量，
+ * 参数中缺少或多出变量时不发送，返回 *TemplateParamError
+ * @param cacheTTL 模板内容的缓存时间，小于等于 0 时使用 DefaultTemplateCacheTTL
+ */
+func WithTemplateValidation(cacheTTL time.Duration) Option {
+	return func(o *clientOptions) error {
+		o.validateParams = true
+		o.templateCacheTTL = cacheTTL
+		return nil
+	}
+}
+
+// WithDedupStore
+/** 按幂等键（SendRequest.IdempotencyKey）去重，调用方超时重试时不会重复发送
+ * @param store 幂等记录存储，例如 NewMemoryDedupStore()，多个进程共享时使用基于 Redis 或数据库的实现
+ * @param ttl 幂等键的保留时间，小于等于 0 时使用 DefaultDedupTTL
+ */
+func WithDedupStore(store DedupStore, ttl time.Duration) Option {
+	return func(o *clientOptions) error {
+		if store == nil {
+			return fmt.Errorf("幂等记录存储不能为空")
+		}
+		if ttl <= 0 {
+			ttl = DefaultDedupTTL
+		}
+		o.dedup = store
+		o.dedupTTL = ttl
+		return nil
+	}
+}
+
+// WithRetryPolicy
+/** 发送短信失败时按业务编码判断是否重试，只重试网络错误、5xx、isp.SYSTEM_ERROR 和限流错误，
+ * 重试前按指数退避加随机抖动等待，结果中的 Attempts 为实际请求次数；
+ * 与 WithAutoRetry 不同，WithAutoRetry 由底层 SDK 重试且不识别业务编码，两者同时配置时发送短信使用该策略
+ * @param policy 重试策略，零值使用默认配置（最多请求 3 次，基础等待 200ms，最长等待 5s）
+ */
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *clientOptions) error {
+		retry, err := newRetryPolicy(policy)
+		if err != nil {
+			return err
+		}
+		o.retry = retry
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+package alibaba
+
+import (
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+func TestWithEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		protocol string
+		valid    bool
+	}{
+		{"dysmsapi.aliyuncs.com", "dysmsapi.aliyuncs.com", DefaultProtocol, true},
+		{" 127.0.0.1:8080 ", "127.0.0.1:8080", DefaultProtocol, true},
+		{"http://127.0.0.1:8080", "127.0.0.1:8080", "http", true},
+		{"HTTPS://dysmsapi.aliyuncs.com/", "dysmsapi.aliyuncs.com", "https", true},
+		{"", "", "", false},
+		{"ftp://dysmsapi.aliyuncs.com", "", "", false},
+		{"http://127.0.0.1:8080/api", "", "", false},
+		{"http://127.0.0.1:8080?a=1", "", "", false},
+		{"127.0.0.1:0", "", "", false},
+		{"127.0.0.1:70000", "", "", false},
+		{"dysmsapi aliyuncs.com", "", "", false},
+		{"user@dysmsapi.aliyuncs.com", "", "", false},
+	}
+	for _, tt := range tests {
+		options, err := newClientOptions([]Option{WithEndpoint(tt.endpoint)})
+		if (err == nil) != tt.valid {
+			t.Errorf("WithEndpoint(%q) error = %v, want valid %v", tt.endpoint, err, tt.valid)
+			continue
+		}
+		if err == nil && (options.endpoint != tt.want || options.protocol != tt.protocol) {
+			t.Errorf("WithEndpoint(%q) = %s %s, want %s %s", tt.endpoint, options.protocol, options.endpoint, tt.protocol, tt.want)
+		}
+	}
+}
+
+func TestWithRegionProtocolNetwork(t *testing.T) {
+	tests := []struct {
+		name  string
+		opt   Option
+		valid bool
+	}{
+		{"region", WithRegionId("ap-southeast-1"), true},
+		{"region with spaces", WithRegionId(" cn-hangzhou "), true},
+		{"empty region", WithRegionId(""), false},
+		{"upper case region", WithRegionId("CN-HANGZHOU"), false},
+		{"region without dash", WithRegionId("cnhangzhou"), false},
+		{"http", WithProtocol("HTTP"), true},
+		{"ftp", WithProtocol("ftp"), false},
+		{"vpc", WithNetwork("VPC"), true},
+		{"unknown network", WithNetwork("lan"), false},
+	}
+	for _, tt := range tests {
+		if _, err := newClientOptions([]Option{tt.opt}); (err == nil) != tt.valid {
+			t.Errorf("%s: error = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+
+	options, err := newClientOptions([]Option{WithRegionId(" ap-southeast-1 "), WithProtocol(" HTTP "), WithNetwork("VPC")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := options.config()
+	if tea.StringValue(config.RegionId) != "ap-southeast-1" || tea.StringValue(config.Protocol) != "http" || tea.StringValue(config.Network) != "vpc" {
+		t.Fatalf("config = region %s, protocol %s, network %s", tea.StringValue(config.RegionId), tea.StringValue(config.Protocol), tea.StringValue(config.Network))
+	}
+	// 公网不设置 Network，由 SDK 使用默认服务地址
+	if defaults, _ := newClientOptions(nil); defaults.config().Network != nil {
+		t.Fatalf("default network = %s", tea.StringValue(defaults.config().Network))
+	}
+}
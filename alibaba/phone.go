@@ -0,0 +1,102 @@
+package alibaba
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidPhoneNumber 手机号码不合法，可以通过 errors.Is 判断
+var ErrInvalidPhoneNumber = errors.New("手机号码不合法")
+
+var (
+	// 中国大陆手机号码：11 位，以 13-19 开头
+	mainlandMobilePattern = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	// E.164 国际号码（不含 +）：国家码不以 0 开头，最多 15 位
+	e164Pattern = regexp.MustCompile(`^[1-9]\d{6,14}$`)
+	// 号码中允许出现的分隔符
+	phoneSeparatorReplacer = strings.NewReplacer(" ", "", "　", "", "\t", "", "-", "", "(", "", ")", "", ".", "")
+)
+
+// PhoneNumberError 手机号码不合法
+type PhoneNumberError struct {
+	// 原始输入的手机号码
+	PhoneNumber string
+	// 不合法的原因
+	Reason string
+}
+
+func (e *PhoneNumberError) Error() string {
+	if strings.TrimSpace(e.PhoneNumber) == "" {
+		return "手机号码为空"
+	}
+	return fmt.Sprintf("手机号码 %s 不合法：%s", e.PhoneNumber, e.Reason)
+}
+
+func (e *PhoneNumberError) Unwrap() error {
+	return ErrInvalidPhoneNumber
+}
+
+// NormalizePhoneNumber
+/** 规范化并校验国内短信接口（SendSms、QuerySendDetails 等）使用的手机号码
+ * 去掉空格、短横线、括号等分隔符后：
+ *	+86 / 0086 / 86 开头的号码去掉国家码，按中国大陆手机号码校验，例如 +86 138-0000-0000 返回 13800000000
+ *	1 开头的号码按中国大陆手机号码校验（11 位，以 13-19 开头）
+ *	+ / 00 开头或其他号码按 E.164 国际号码校验，返回不含 + 的号码，例如 +852 9000 0000 返回 85290000000
+ * 国内短信接口把 1 开头的号码当成中国大陆号码，国家码为 1 的国际号码（例如 +1 650 253 0000）无法与之区分，
+ * 因此不合法，需要通过 SendGlobe 发送（见 GlobeNumber）
+ * @param phoneNumber 手机号码
+ * @return string 规范化后的号码
+ * @return error 号码不合法时返回 *PhoneNumberError
+ */
+func NormalizePhoneNumber(phoneNumber string) (string, error) {
+	number, international, err := parsePhoneNumber(phoneNumber)
+	if err != nil {
+		return "", err
+	}
+	if international && strings.HasPrefix(number, "1") {
+		return "", &PhoneNumberError{PhoneNumber: phoneNumber, Reason: "国家码为 1 的国际号码会被国内短信接口当成中国大陆号码，请使用国际短信接口发送"}
+	}
+	return number, nil
+}
+
+// 规范化并校验手机号码，international 表示输入带有 86 以外的国家码（+ 或 00 开头）
+// 中国大陆号码返回不含国家码的 11 位号码，国际号码返回不含 + 的国家码加号码
+func parsePhoneNumber(phoneNumber string) (number string, international bool, err error) {
+	number = phoneSeparatorReplacer.Replace(strings.TrimSpace(phoneNumber))
+	invalid := func(reason string) (string, bool, error) {
+		return "", false, &PhoneNumberError{PhoneNumber: phoneNumber, Reason: reason}
+	}
+	if number == "" {
+		return invalid("号码为空")
+	}
+	switch {
+	case strings.HasPrefix(number, "+"):
+		number, international = number[1:], true
+	case strings.HasPrefix(number, "00"):
+		number, international = number[2:], true
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return invalid("包含非数字字符")
+		}
+	}
+	// 以 86 开头的国家码只有中国大陆
+	if national := strings.TrimPrefix(number, "86"); national != number {
+		if !mainlandMobilePattern.MatchString(national) {
+			return invalid("不是有效的中国大陆手机号码")
+		}
+		return national, false, nil
+	}
+	if !international && strings.HasPrefix(number, "1") {
+		if !mainlandMobilePattern.MatchString(number) {
+			return invalid("不是有效的中国大陆手机号码（11 位，以 13-19 开头）")
+		}
+		return number, false, nil
+	}
+	if !e164Pattern.MatchString(number) {
+		return invalid("不是有效的 E.164 国际号码")
+	}
+	return number, international, nil
+}
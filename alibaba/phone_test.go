@@ -0,0 +1,62 @@
+package alibaba
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"13800000000", "13800000000"},
+		{" 138-0000-0000 ", "13800000000"},
+		{"+86 138 0000 0000", "13800000000"},
+		{"0086 13800000000", "13800000000"},
+		{"8613800000000", "13800000000"},
+		{"+852 9000 0000", "85290000000"},
+		{"00 65 9123 4567", "6591234567"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePhoneNumber(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizePhoneNumber(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizePhoneNumberInvalid(t *testing.T) {
+	for _, input := range []string{"", "   ", "1380000000", "12800000000", "+86 12800000000", "138abc00000", "+0123456789", "12345",
+		// 国家码为 1 的号码会被国内短信接口当成中国大陆号码
+		"+1 650 253 0000", "001 (415) 555-0100", "+14155550100"} {
+		_, err := NormalizePhoneNumber(input)
+		var phoneErr *PhoneNumberError
+		if !errors.As(err, &phoneErr) || !errors.Is(err, ErrInvalidPhoneNumber) || phoneErr.PhoneNumber != input {
+			t.Errorf("NormalizePhoneNumber(%q) error = %v, want *PhoneNumberError", input, err)
+		}
+	}
+}
+
+func TestParsePhoneNumber(t *testing.T) {
+	tests := []struct {
+		input         string
+		want          string
+		international bool
+	}{
+		{"13800000000", "13800000000", false},
+		{"+86 138 0000 0000", "13800000000", false},
+		{"8613800000000", "13800000000", false},
+		{"+1 650 253 0000", "16502530000", true},
+		{"001 (415) 555-0100", "14155550100", true},
+		{"+65 9123 4567", "6591234567", true},
+		// 不带 + 或 00 的 86 以外国家码无法与号码区分，按号码本身校验
+		{"85290000000", "85290000000", false},
+	}
+	for _, tt := range tests {
+		got, international, err := parsePhoneNumber(tt.input)
+		if err != nil || got != tt.want || international != tt.international {
+			t.Errorf("parsePhoneNumber(%q) = %q, %v, %v, want %q, %v", tt.input, got, international, err, tt.want, tt.international)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package alibaba
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket 令牌桶限流器
+/**
+ * 以固定速率向桶中放入令牌，桶的容量为 burst，每次调用取走一个令牌，没有令牌时等待；
+ * WithRateLimit 和 FlowLimiter 的各条限流规则都使用该实现，FlowLimiter 在自己的锁内调用 available 和 take
+ */
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket
+/** 创建令牌桶
+ * @param qps 每秒放入的令牌数
+ * @param burst 桶的容量，小于 1 时按 1 处理
+ */
+func newTokenBucket(qps float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   qps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// now 时可用的令牌数，调用方需持有锁
+func (b *tokenBucket) available(now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.last).Seconds()*b.rate
+	if tokens > b.burst {
+		tokens = b.burst
+	}
+	return tokens
+}
+
+// 取走 n 个令牌，令牌不足时记为负数，调用方需持有锁
+func (b *tokenBucket) take(now time.Time, n float64) {
+	b.tokens = b.available(now) - n
+	b.last = now
+}
+
+// 令牌数达到 n 还需要等待的时间，调用方需持有锁
+func (b *tokenBucket) delay(now time.Time, n float64) time.Duration {
+	missing := n - b.available(now)
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / b.rate * float64(time.Second))
+}
+
+// 取走一个令牌，返回拿到令牌前需要等待的时间
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.take(now, 1)
+	return b.delay(now, 0)
+}
+
+// 归还一个未使用的令牌
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// 等待直到拿到一个令牌，ctx 被取消时归还令牌并返回包装后的 ctx.Err()
+func (b *tokenBucket) wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return contextError(ctx.Err())
+	}
+}
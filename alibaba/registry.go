@@ -0,0 +1,353 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"third_party_tool_library"
+)
+
+var (
+	// ErrTenantNotFound 租户未注册
+	ErrTenantNotFound = errors.New("租户未注册")
+	// ErrTenantExists 租户已注册
+	ErrTenantExists = errors.New("租户已注册")
+)
+
+// TenantConfig 租户配置
+/**
+ * 每个租户使用自己的阿里云短信账号，Provider 不为空时优先使用 Provider，否则使用 AccessKeyId 与 AccessKeySecret
+ */
+type TenantConfig struct {
+	// AccessKey ID
+	AccessKeyId string
+	// AccessKey Secret
+	AccessKeySecret string
+	// 凭证提供者，例如 AssumeRoleCredentialProvider
+	Provider CredentialProvider
+	// 地域 ID，为空时使用 Options 中的配置或 DefaultRegionId
+	RegionId string
+	// 默认短信签名，发送时未指定签名则使用该签名
+	DefaultSignName string
+	// 客户端配置项，例如 WithEndpoint、WithReadTimeout
+	Options []Option
+}
+
+// 租户的凭证提供者
+func (c *TenantConfig) provider() (CredentialProvider, error) {
+	if c.Provider != nil {
+		return c.Provider, nil
+	}
+	if c.AccessKeyId == "" || c.AccessKeySecret == "" {
+		return nil, errors.New("租户凭证不能为空")
+	}
+	return NewStaticCredentialProvider(c.AccessKeyId, c.AccessKeySecret, ""), nil
+}
+
+// 租户的客户端配置项，RegionId、DefaultSignName 覆盖 Options 中的对应配置
+func (c *TenantConfig) options() []Option {
+	opts := append([]Option(nil), c.Options...)
+	if c.RegionId != "" {
+		opts = append(opts, WithRegionId(c.RegionId))
+	}
+	if c.DefaultSignName != "" {
+		opts = append(opts, WithSignName(c.DefaultSignName))
+	}
+	return opts
+}
+
+// 校验租户配置
+func (c *TenantConfig) validate() error {
+	if _, err := c.provider(); err != nil {
+		return err
+	}
+	_, err := newClientOptions(c.options())
+	return err
+}
+
+type tenantEntry struct {
+	config TenantConfig
+	// 保护 client 与 lastUsed，创建客户端期间持有，避免同一租户并发创建多个客户端
+	mu       sync.Mutex
+	client   *Client
+	lastUsed time.Time
+}
+
+// Registry 多租户客户端注册表
+/**
+ * 以租户 ID 管理各租户的凭证与客户端配置，客户端在首次使用时创建并缓存，可在多个 goroutine 中并发使用。
+ * 长时间未使用的租户会释放缓存的客户端（租户配置保留），再次使用时重新创建。
+ */
+type Registry struct {
+	mu      sync.RWMutex
+	tenants map[string]*tenantEntry
+
+	idleTimeout time.Duration
+	stop        chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewRegistry
+/** 创建多租户客户端注册表
+ * @param idleTimeout 租户客户端的空闲时间，超过该时间未使用的客户端会在后台被释放；小于等于 0 时不自动释放
+ * @return *Registry 注册表，不再使用时调用 Close 停止后台清理
+ */
+func NewRegistry(idleTimeout time.Duration) *Registry {
+	r := &Registry{
+		tenants:     make(map[string]*tenantEntry),
+		idleTimeout: idleTimeout,
+		stop:        make(chan struct{}),
+	}
+	if idleTimeout > 0 {
+		go r.janitor()
+	}
+	return r
+}
+
+// 定期释放空闲的租户客户端
+func (r *Registry) janitor() {
+	interval := r.idleTimeout / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.EvictIdle(r.idleTimeout)
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Close 停止后台清理，已注册的租户仍可继续使用
+func (r *Registry) Close() {
+	r.closeOnce.Do(func() { close(r.stop) })
+}
+
+// Add
+/** 注册租户
+ * @param tenantId 租户 ID
+ * @param config 租户配置
+ * @return error 租户已存在时返回 ErrTenantExists，配置不合法时返回对应错误
+ */
+func (r *Registry) Add(tenantId string, config TenantConfig) error {
+	if tenantId == "" {
+		return errors.New("租户 ID 不能为空")
+	}
+	if err := config.validate(); err != nil {
+		return fmt.Errorf("租户 %s 配置不合法：%w", tenantId, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tenants[tenantId]; ok {
+		return fmt.Errorf("%w：%s", ErrTenantExists, tenantId)
+	}
+	r.tenants[tenantId] = &tenantEntry{config: config}
+	return nil
+}
+
+// Update
+/** 更新租户配置，已缓存的客户端会被丢弃，下次使用时按新配置创建
+ * @param tenantId 租户 ID
+ * @param config 租户配置
+ * @return error 租户不存在时返回 ErrTenantNotFound，配置不合法时返回对应错误
+ */
+func (r *Registry) Update(tenantId string, config TenantConfig) error {
+	if err := config.validate(); err != nil {
+		return fmt.Errorf("租户 %s 配置不合法：%w", tenantId, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tenants[tenantId]; !ok {
+		return fmt.Errorf("%w：%s", ErrTenantNotFound, tenantId)
+	}
+	r.tenants[tenantId] = &tenantEntry{config: config}
+	return nil
+}
+
+// Remove
+/** 移除租户，正在进行中的调用不受影响
+ * @param tenantId 租户 ID
+ * @return bool 租户是否存在
+ */
+func (r *Registry) Remove(tenantId string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.tenants[tenantId]
+	delete(r.tenants, tenantId)
+	return ok
+}
+
+// Tenants 已注册的租户 ID 列表（按字典序）
+func (r *Registry) Tenants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.tenants))
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (r *Registry) entry(tenantId string) (*tenantEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.tenants[tenantId]
+	if !ok {
+		return nil, fmt.Errorf("%w：%s", ErrTenantNotFound, tenantId)
+	}
+	return e, nil
+}
+
+// 获取租户的客户端，未创建时按租户配置创建
+func (e *tenantEntry) getClient() (*Client, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.client == nil {
+		provider, err := e.config.provider()
+		if err != nil {
+			return nil, err
+		}
+		client, err := NewClientWithProvider(provider, e.config.options()...)
+		if err != nil {
+			return nil, err
+		}
+		e.client = client
+	}
+	e.lastUsed = time.Now()
+	return e.client, nil
+}
+
+// Client
+/** 获取租户的短信客户端，首次使用时创建并缓存
+ * @param tenantId 租户 ID
+ * @return *Client 短信客户端
+ * @return error 租户不存在时返回 ErrTenantNotFound
+ */
+func (r *Registry) Client(tenantId string) (*Client, error) {
+	e, err := r.entry(tenantId)
+	if err != nil {
+		return nil, err
+	}
+	client, err := e.getClient()
+	if err != nil {
+		return nil, fmt.Errorf("创建租户 %s 的短信客户端失败：%w", tenantId, err)
+	}
+	return client, nil
+}
+
+// EvictIdle
+/** 释放超过 idle 时间未使用的租户客户端，租户配置保留
+ * @param idle 空闲时间
+ * @return int 释放的客户端数量
+ */
+func (r *Registry) EvictIdle(idle time.Duration) int {
+	r.mu.RLock()
+	entries := make([]*tenantEntry, 0, len(r.tenants))
+	for _, e := range r.tenants {
+		entries = append(entries, e)
+	}
+	r.mu.RUnlock()
+
+	deadline := time.Now().Add(-idle)
+	evicted := 0
+	for _, e := range entries {
+		e.mu.Lock()
+		if e.client != nil && e.lastUsed.Before(deadline) {
+			e.client = nil
+			evicted++
+		}
+		e.mu.Unlock()
+	}
+	return evicted
+}
+
+// Send 按租户发送短信
+/**
+ * 参数含义同 Client.Send，请求中没有指定签名时使用租户的默认签名
+ * @param tenantId 租户 ID
+ * @return int32 接口响应编码，租户不存在时为 404
+ * @return third_party_tool_library.ResponseResult 响应对象（第三方返回的响应信息都在里面，包含业务错误）
+ * @return error 错误响应对象
+ */
+func (r *Registry) Send(tenantId string, req SendRequest, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	return r.SendContext(context.Background(), tenantId, req, opts...)
+}
+
+// SendContext
+/** 按租户发送短信，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 Registry.Send
+ */
+func (r *Registry) SendContext(ctx context.Context, tenantId string, req SendRequest, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	client, code, err := r.sendClient(tenantId)
+	if err != nil {
+		return code, third_party_tool_library.ResponseResult{}, err
+	}
+	return client.SendContext(ctx, req, opts...)
+}
+
+// SendBatch 按租户分片发送短信
+/**
+ * 参数与返回值同 Client.SendBatch，租户不存在时响应编码为 404
+ * @param tenantId 租户 ID
+ */
+func (r *Registry) SendBatch(tenantId string, req SendRequest, concurrency int, opts ...CallOption) (int32, BatchResult, error) {
+	return r.SendBatchContext(context.Background(), tenantId, req, concurrency, opts...)
+}
+
+// SendBatchContext
+/** 按租户分片发送短信，ctx 被取消后尚未发送的分片不再发送
+ * 参数与返回值同 Registry.SendBatch
+ */
+func (r *Registry) SendBatchContext(ctx context.Context, tenantId string, req SendRequest, concurrency int, opts ...CallOption) (int32, BatchResult, error) {
+	client, code, err := r.sendClient(tenantId)
+	if err != nil {
+		return code, BatchResult{}, err
+	}
+	return client.SendBatchContext(ctx, req, concurrency, opts...)
+}
+
+// SmsSend 按租户发送短信
+/**
+ * 参数含义同 Client.SmsSend，signName 为空时使用租户的默认签名
+ * @param tenantId 租户 ID
+ * @return int32 接口响应编码，租户不存在时为 404
+ * @return third_party_tool_library.ResponseResult 响应对象（第三方返回的响应信息都在里面，包含业务错误）
+ * @return error 错误响应对象
+ */
+func (r *Registry) SmsSend(tenantId, phoneNumbers, signName, templateCode, templateParam string, isBatchSend bool, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	return r.SmsSendContext(context.Background(), tenantId, phoneNumbers, signName, templateCode, templateParam, isBatchSend, opts...)
+}
+
+// SmsSendContext
+/** 按租户发送短信，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 Registry.SmsSend
+ */
+func (r *Registry) SmsSendContext(ctx context.Context, tenantId, phoneNumbers, signName, templateCode, templateParam string, isBatchSend bool, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	client, code, err := r.sendClient(tenantId)
+	if err != nil {
+		return code, third_party_tool_library.ResponseResult{}, err
+	}
+	return client.SmsSendContext(ctx, phoneNumbers, signName, templateCode, templateParam, isBatchSend, opts...)
+}
+
+// 获取发送短信使用的租户客户端，失败时同时返回对应的响应编码
+func (r *Registry) sendClient(tenantId string) (*Client, int32, error) {
+	e, err := r.entry(tenantId)
+	if err != nil {
+		return nil, 404, err
+	}
+	client, err := e.getClient()
+	if err != nil {
+		return nil, 500, fmt.Errorf("创建租户 %s 的短信客户端失败：%w", tenantId, err)
+	}
+	return client, 200, nil
+}
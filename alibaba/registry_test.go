@@ -0,0 +1,235 @@
+package alibaba
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+func TestRegistryTenants(t *testing.T) {
+	registry := NewRegistry(0)
+	defer registry.Close()
+
+	config := TenantConfig{AccessKeyId: "id", AccessKeySecret: "secret"}
+	if err := registry.Add("b", config); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add("a", config); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add("a", config); !errors.Is(err, ErrTenantExists) {
+		t.Fatalf("Add() duplicate = %v, want ErrTenantExists", err)
+	}
+	if err := registry.Add("", config); err == nil {
+		t.Fatal("empty tenant ID was accepted")
+	}
+	if err := registry.Add("c", TenantConfig{AccessKeyId: "id"}); err == nil {
+		t.Fatal("tenant without secret was accepted")
+	}
+	if err := registry.Add("c", TenantConfig{AccessKeyId: "id", AccessKeySecret: "secret", RegionId: "Hangzhou"}); err == nil {
+		t.Fatal("tenant with invalid region was accepted")
+	}
+	if ids := registry.Tenants(); !reflect.DeepEqual(ids, []string{"a", "b"}) {
+		t.Fatalf("Tenants() = %v", ids)
+	}
+
+	if err := registry.Update("c", config); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("Update() unknown = %v, want ErrTenantNotFound", err)
+	}
+	if !registry.Remove("a") || registry.Remove("a") {
+		t.Fatal("Remove() did not report whether the tenant existed")
+	}
+	if _, err := registry.Client("a"); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("Client() removed = %v, want ErrTenantNotFound", err)
+	}
+}
+
+func TestRegistryClientPerTenant(t *testing.T) {
+	var mu sync.Mutex
+	var requests []url.Values
+	server := newSmsServer(t, func(params url.Values) map[string]string {
+		mu.Lock()
+		requests = append(requests, params)
+		mu.Unlock()
+		return okResponse("biz")
+	})
+	registry := NewRegistry(0)
+	defer registry.Close()
+	options := []Option{WithEndpoint(server.URL)}
+	if err := registry.Add("a", TenantConfig{AccessKeyId: "id-a", AccessKeySecret: "secret-a", RegionId: "cn-shanghai", DefaultSignName: "签名A", Options: options}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add("b", TenantConfig{Provider: NewStaticCredentialProvider("id-b", "secret-b", ""), DefaultSignName: "签名B", Options: options}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 客户端在首次使用时创建，之后复用
+	a, err := registry.Client("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := registry.Client("a"); again != a {
+		t.Fatal("Client() created a new client for the same tenant")
+	}
+	b, err := registry.Client("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Fatal("tenants share a client")
+	}
+	if region := tea.StringValue(a.sms.RegionId); region != "cn-shanghai" {
+		t.Fatalf("tenant a region = %s", region)
+	}
+	if region := tea.StringValue(b.sms.RegionId); region != DefaultRegionId {
+		t.Fatalf("tenant b region = %s", region)
+	}
+	for client, want := range map[*Client]string{a: "id-a", b: "id-b"} {
+		if credential, err := client.credential.resolve(); err != nil || credential.AccessKeyId != want {
+			t.Fatalf("credential = %+v, %v, want %s", credential, err, want)
+		}
+	}
+
+	for _, tenant := range []string{"a", "b"} {
+		if statusCode, _, err := registry.Send(tenant, SendRequest{Recipients: Recipients("13800000000"), TemplateCode: "SMS_1"}); statusCode != 200 || err != nil {
+			t.Fatalf("Send(%s) = %d, %v", tenant, statusCode, err)
+		}
+	}
+	for i, want := range []string{"签名A", "签名B"} {
+		if sign := requests[i].Get("SignName"); sign != want {
+			t.Errorf("request %d SignName = %s, want %s", i, sign, want)
+		}
+	}
+
+	// 请求中指定的签名优先于租户默认签名
+	if _, _, err := registry.Send("a", SendRequest{Recipients: Recipients("13800000000"), SignName: "自定义", TemplateCode: "SMS_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if sign := requests[len(requests)-1].Get("SignName"); sign != "自定义" {
+		t.Fatalf("SignName = %s", sign)
+	}
+
+	// 更新配置后丢弃旧客户端
+	if err := registry.Update("a", TenantConfig{AccessKeyId: "id-a2", AccessKeySecret: "secret-a2", Options: options}); err != nil {
+		t.Fatal(err)
+	}
+	if updated, _ := registry.Client("a"); updated == a {
+		t.Fatal("Update() kept the old client")
+	}
+}
+
+func TestRegistryUnknownTenant(t *testing.T) {
+	registry := NewRegistry(0)
+	defer registry.Close()
+
+	req := SendRequest{Recipients: Recipients("13800000000"), TemplateCode: "SMS_1"}
+	if statusCode, _, err := registry.Send("missing", req); statusCode != 404 || !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("Send() = %d, %v, want 404 and ErrTenantNotFound", statusCode, err)
+	}
+	if statusCode, _, err := registry.SendBatch("missing", req, 2); statusCode != 404 || !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("SendBatch() = %d, %v, want 404 and ErrTenantNotFound", statusCode, err)
+	}
+	if statusCode, _, err := registry.SmsSend("missing", "13800000000", "", "SMS_1", "", false); statusCode != 404 || !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("SmsSend() = %d, %v, want 404 and ErrTenantNotFound", statusCode, err)
+	}
+}
+
+func TestRegistryEvictIdle(t *testing.T) {
+	registry := NewRegistry(0)
+	defer registry.Close()
+	for _, tenant := range []string{"a", "b"} {
+		if err := registry.Add(tenant, TenantConfig{AccessKeyId: "id", AccessKeySecret: "secret"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, err := registry.Client("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := registry.EvictIdle(time.Hour); n != 0 {
+		t.Fatalf("EvictIdle(1h) = %d, want 0", n)
+	}
+	// 只释放已创建的客户端，租户配置保留
+	time.Sleep(10 * time.Millisecond)
+	if n := registry.EvictIdle(time.Millisecond); n != 1 {
+		t.Fatalf("EvictIdle(1ms) = %d, want 1", n)
+	}
+	if ids := registry.Tenants(); len(ids) != 2 {
+		t.Fatalf("Tenants() = %v", ids)
+	}
+	if recreated, err := registry.Client("a"); err != nil || recreated == a {
+		t.Fatalf("Client() after eviction = %p, %v", recreated, err)
+	}
+}
+
+func TestRegistryJanitor(t *testing.T) {
+	registry := NewRegistry(time.Millisecond)
+	defer registry.Close()
+	if err := registry.Add("a", TenantConfig{AccessKeyId: "id", AccessKeySecret: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Client("a"); err != nil {
+		t.Fatal(err)
+	}
+	e, _ := registry.entry("a")
+	// 清理间隔最短为 1 秒
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		e.mu.Lock()
+		evicted := e.client == nil
+		e.mu.Unlock()
+		if evicted {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("janitor did not evict the idle client")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	registry.Close()
+	registry.Close()
+}
+
+func TestRegistryConcurrentUpdate(t *testing.T) {
+	server := newSmsServer(t, func(url.Values) map[string]string { return okResponse("biz") })
+	registry := NewRegistry(0)
+	defer registry.Close()
+	config := TenantConfig{AccessKeyId: "id", AccessKeySecret: "secret", DefaultSignName: "签名", Options: []Option{WithEndpoint(server.URL)}}
+	if err := registry.Add("a", config); err != nil {
+		t.Fatal(err)
+	}
+
+	req := SendRequest{Recipients: Recipients("13800000000"), TemplateCode: "SMS_1"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				statusCode, _, err := registry.Send("a", req)
+				// 租户被移除期间返回 404，其余请求都应发送成功
+				if statusCode != 200 && !(statusCode == 404 && errors.Is(err, ErrTenantNotFound)) {
+					t.Errorf("Send() = %d, %v", statusCode, err)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		if err := registry.Update("a", config); err != nil && !errors.Is(err, ErrTenantNotFound) {
+			t.Error(err)
+		}
+		if i%5 == 0 {
+			registry.Remove("a")
+			if err := registry.Add("a", config); err != nil {
+				t.Error(err)
+			}
+		}
+		registry.EvictIdle(0)
+	}
+	wg.Wait()
+}
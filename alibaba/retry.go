@@ -0,0 +1,190 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"third_party_tool_library"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+const (
+	// DefaultRetryMaxAttempts 默认最多请求次数（包含第一次）
+	DefaultRetryMaxAttempts = 3
+	// DefaultRetryBaseDelay 默认的指数退避基础时间
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+	// DefaultRetryMaxDelay 默认的单次最长等待时间
+	DefaultRetryMaxDelay = 5 * time.Second
+)
+
+// 默认可重试的业务编码：系统错误和接口限流
+var defaultRetryableCodes = []string{
+	"isp.SYSTEM_ERROR",
+	"Throttling",
+	"Throttling.Api",
+	"Throttling.User",
+	"ServiceUnavailable",
+}
+
+// 永不重试的业务编码：请求本身有问题，重试不会成功
+var permanentErrorCodes = map[string]bool{
+	"isv.MOBILE_NUMBER_ILLEGAL":       true,
+	"isv.TEMPLATE_MISSING_PARAMETERS": true,
+	"isv.INVALID_PARAMETERS":          true,
+	"isv.SMS_SIGNATURE_ILLEGAL":       true,
+	"isv.SMS_TEMPLATE_ILLEGAL":        true,
+	"isv.TEMPLATE_PARAMS_ILLEGAL":     true,
+	"isv.AMOUNT_NOT_ENOUGH":           true,
+	"isv.BLACK_KEY_CONTROL_LIMIT":     true,
+	"isv.MOBILE_COUNT_OVER_LIMIT":     true,
+}
+
+// IsPermanentError 业务编码是否表示请求本身有误（例如 isv.MOBILE_NUMBER_ILLEGAL），重试不会成功
+func IsPermanentError(code string) bool {
+	return permanentErrorCodes[code]
+}
+
+// RetryPolicy 发送短信的重试策略
+/**
+ * 只重试可能自行恢复的失败：网络错误、服务端 5xx 错误、isp.SYSTEM_ERROR 和接口限流（Throttling 等），
+ * 号码不合法、模板参数缺失等请求本身的错误（例如 isv.MOBILE_NUMBER_ILLEGAL、isv.TEMPLATE_MISSING_PARAMETERS）从不重试；
+ * 每次重试前按指数退避等待，并加入随机抖动，避免大量请求同时重试
+ * 注意：网络错误时短信可能已经发出，重试可能导致重复发送，需要避免时请同时使用幂等键（见 WithDedupStore）
+ */
+type RetryPolicy struct {
+	// 最多请求次数（包含第一次），为 0 时使用 DefaultRetryMaxAttempts，为 1 表示不重试
+	MaxAttempts int
+	// 指数退避的基础时间，第 n 次重试前最多等待 BaseDelay * 2^(n-1)，为 0 时使用 DefaultRetryBaseDelay
+	BaseDelay time.Duration
+	// 单次最长等待时间，为 0 时使用 DefaultRetryMaxDelay
+	MaxDelay time.Duration
+	// 额外可重试的业务编码，不能包含永不重试的业务编码
+	RetryableCodes []string
+}
+
+// 生效的重试策略
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	codes       map[string]bool
+}
+
+func newRetryPolicy(policy RetryPolicy) (*retryPolicy, error) {
+	if policy.MaxAttempts < 0 || policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+		return nil, errors.New("重试次数和等待时间不能小于 0")
+	}
+	p := &retryPolicy{
+		maxAttempts: policy.MaxAttempts,
+		baseDelay:   policy.BaseDelay,
+		maxDelay:    policy.MaxDelay,
+		codes:       make(map[string]bool),
+	}
+	if p.maxAttempts == 0 {
+		p.maxAttempts = DefaultRetryMaxAttempts
+	}
+	if p.baseDelay == 0 {
+		p.baseDelay = DefaultRetryBaseDelay
+	}
+	if p.maxDelay == 0 {
+		p.maxDelay = DefaultRetryMaxDelay
+	}
+	if p.maxDelay < p.baseDelay {
+		return nil, errors.New("单次最长等待时间不能小于退避基础时间")
+	}
+	for _, code := range defaultRetryableCodes {
+		p.codes[code] = true
+	}
+	for _, code := range policy.RetryableCodes {
+		if permanentErrorCodes[code] {
+			return nil, fmt.Errorf("业务编码 %s 表示请求本身有误，不能重试", code)
+		}
+		p.codes[code] = true
+	}
+	return p, nil
+}
+
+// 判断失败是否可以重试
+func (p *retryPolicy) retryable(statusCode int32, result third_party_tool_library.ResponseResult, err error) bool {
+	if err != nil {
+		if errors.Is(err, ErrCircuitOpen) {
+			return false
+		}
+		var sdkErr *tea.SDKError
+		if errors.As(err, &sdkErr) {
+			return tea.IntValue(sdkErr.StatusCode) >= 500 || p.codes[tea.StringValue(sdkErr.Code)]
+		}
+		// 网络错误
+		return true
+	}
+	if statusCode >= 500 {
+		return true
+	}
+	return p.codes[tea.StringValue(result.Code)]
+}
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// 第 attempt 次重试前的等待时间：在指数退避时间的一半到全部之间随机
+func (p *retryPolicy) delay(attempt int) time.Duration {
+	backoff := p.baseDelay
+	for i := 1; i < attempt && backoff < p.maxDelay; i++ {
+		backoff *= 2
+	}
+	if backoff > p.maxDelay {
+		backoff = p.maxDelay
+	}
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return backoff/2 + time.Duration(jitterRand.Int63n(int64(backoff/2)+1))
+}
+
+// 按重试策略执行发送，结果中的 Attempts 为实际请求次数
+/**
+ * 未配置重试策略时只请求一次；配置了重试策略时关闭底层 SDK 的自动重试，避免重复计数
+ * ctx 被取消时停止重试，返回最后一次的结果或包装后的 ctx.Err()
+ * 之前的请求结果未知（例如超时，短信可能已经发出）而之后的请求确定失败时，返回结果未知的那次错误，避免调用方误以为短信没有发出
+ */
+func (c *Client) withRetry(ctx context.Context, opts []CallOption, send func(opts []CallOption) (int32, third_party_tool_library.ResponseResult, error)) (int32, third_party_tool_library.ResponseResult, error) {
+	if c.retry == nil {
+		statusCode, result, err := send(opts)
+		result.Attempts = 1
+		return statusCode, result, err
+	}
+	opts = append(opts[:len(opts):len(opts)], CallAutoRetry(1, 0))
+	// 结果未知的请求的错误
+	var unknown error
+	for attempt := 1; ; attempt++ {
+		statusCode, result, err := send(opts)
+		result.Attempts = attempt
+		if err != nil && !notSent(err) {
+			unknown = err
+		}
+		if attempt >= c.retry.maxAttempts || ctx.Err() != nil || !c.retry.retryable(statusCode, result, err) {
+			return unknownOutcome(statusCode, result, err, unknown)
+		}
+		timer := time.NewTimer(c.retry.delay(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return unknownOutcome(statusCode, result, err, unknown)
+		}
+	}
+}
+
+// 最后一次请求确定失败、之前有请求结果未知时，返回结果未知的错误
+func unknownOutcome(statusCode int32, result third_party_tool_library.ResponseResult, err, unknown error) (int32, third_party_tool_library.ResponseResult, error) {
+	if unknown == nil || err == nil && tea.StringValue(result.Code) == "OK" || err != nil && !notSent(err) {
+		return statusCode, result, err
+	}
+	return 500, third_party_tool_library.ResponseResult{Attempts: result.Attempts}, unknown
+}
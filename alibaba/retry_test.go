@@ -0,0 +1,157 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"third_party_tool_library"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy, err := newRetryPolicy(RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第 n 次重试前的退避时间为 BaseDelay * 2^(n-1)，不超过 MaxDelay
+	backoffs := []time.Duration{100, 200, 400, 800, 1000, 1000, 1000}
+	for i, backoff := range backoffs {
+		attempt := i + 1
+		backoff *= time.Millisecond
+		for j := 0; j < 200; j++ {
+			if delay := policy.delay(attempt); delay < backoff/2 || delay > backoff {
+				t.Fatalf("delay(%d) = %v, want between %v and %v", attempt, delay, backoff/2, backoff)
+			}
+		}
+	}
+	// 重试次数很大时不溢出
+	if delay := policy.delay(1000); delay < 500*time.Millisecond || delay > time.Second {
+		t.Fatalf("delay(1000) = %v", delay)
+	}
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	policy, err := newRetryPolicy(RetryPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.maxAttempts != DefaultRetryMaxAttempts || policy.baseDelay != DefaultRetryBaseDelay || policy.maxDelay != DefaultRetryMaxDelay {
+		t.Fatalf("default policy = %+v", policy)
+	}
+	invalid := []RetryPolicy{
+		{MaxAttempts: -1},
+		{BaseDelay: -time.Second},
+		{BaseDelay: time.Second, MaxDelay: 500 * time.Millisecond},
+		{RetryableCodes: []string{"isv.MOBILE_NUMBER_ILLEGAL"}},
+	}
+	for _, p := range invalid {
+		if _, err := newRetryPolicy(p); err == nil {
+			t.Errorf("newRetryPolicy(%+v) succeeded", p)
+		}
+	}
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	policy, err := newRetryPolicy(RetryPolicy{RetryableCodes: []string{"isv.BUSINESS_LIMIT_CONTROL"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := func(code string) third_party_tool_library.ResponseResult {
+		return third_party_tool_library.ResponseResult{Code: tea.String(code)}
+	}
+	tests := []struct {
+		name       string
+		statusCode int32
+		result     third_party_tool_library.ResponseResult
+		err        error
+		want       bool
+	}{
+		{"system error", 200, result("isp.SYSTEM_ERROR"), nil, true},
+		{"throttling", 200, result("Throttling.User"), nil, true},
+		{"extra code", 200, result("isv.BUSINESS_LIMIT_CONTROL"), nil, true},
+		{"server error", 503, result(""), nil, true},
+		{"illegal number", 200, result("isv.MOBILE_NUMBER_ILLEGAL"), nil, false},
+		{"ok", 200, result("OK"), nil, false},
+		{"network error", 500, result(""), errors.New("connection reset"), true},
+		{"sdk 5xx", 500, result(""), &tea.SDKError{StatusCode: tea.Int(502)}, true},
+		{"sdk throttling", 400, result(""), &tea.SDKError{StatusCode: tea.Int(400), Code: tea.String("Throttling.Api")}, true},
+		{"sdk 4xx", 400, result(""), &tea.SDKError{StatusCode: tea.Int(400), Code: tea.String("InvalidParameter")}, false},
+		{"circuit open", 503, result(""), ErrCircuitOpen, false},
+	}
+	for _, tt := range tests {
+		if got := policy.retryable(tt.statusCode, tt.result, tt.err); got != tt.want {
+			t.Errorf("%s: retryable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownOutcome(t *testing.T) {
+	unknown := errors.New("read timeout")
+	failed := third_party_tool_library.ResponseResult{Code: tea.String("isv.BUSINESS_LIMIT_CONTROL"), Attempts: 3}
+
+	// 之前的请求结果未知、最后一次确定失败时返回结果未知的错误
+	if statusCode, result, err := unknownOutcome(200, failed, nil, unknown); statusCode != 500 || err != unknown || result.Attempts != 3 {
+		t.Fatalf("unknownOutcome(failed) = %d, %+v, %v", statusCode, result, err)
+	}
+	if _, _, err := unknownOutcome(429, failed, ErrRateLimited, unknown); err != unknown {
+		t.Fatalf("unknownOutcome(rate limited) error = %v", err)
+	}
+	// 最后一次成功或结果同样未知时返回最后一次的结果
+	ok := third_party_tool_library.ResponseResult{Code: tea.String("OK")}
+	if statusCode, _, err := unknownOutcome(200, ok, nil, unknown); statusCode != 200 || err != nil {
+		t.Fatalf("unknownOutcome(ok) = %d, %v", statusCode, err)
+	}
+	last := errors.New("connection reset")
+	if _, _, err := unknownOutcome(500, failed, last, unknown); err != last {
+		t.Fatalf("unknownOutcome(unknown) error = %v", err)
+	}
+	if _, _, err := unknownOutcome(200, failed, nil, nil); err != nil {
+		t.Fatalf("unknownOutcome(no unknown) error = %v", err)
+	}
+}
+
+func TestWithRetryAttempts(t *testing.T) {
+	var calls atomic.Int64
+	codes := map[string][]string{
+		"13800000001": {"isp.SYSTEM_ERROR", "Throttling.User", "OK"},
+		"13800000002": {"isv.MOBILE_NUMBER_ILLEGAL"},
+		"13800000003": {"isp.SYSTEM_ERROR", "isp.SYSTEM_ERROR", "isp.SYSTEM_ERROR", "OK"},
+	}
+	var attempt atomic.Int64
+	server := newSmsServer(t, func(params url.Values) map[string]string {
+		calls.Add(1)
+		sequence := codes[params.Get("PhoneNumbers")]
+		code := sequence[int(attempt.Add(1)-1)%len(sequence)]
+		if code == "OK" {
+			return okResponse("biz-1")
+		}
+		return map[string]string{"Code": code, "Message": code}
+	})
+	client := newTestClient(t, server, WithRetryPolicy(RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}))
+
+	tests := []struct {
+		phone    string
+		code     string
+		attempts int
+	}{
+		{"13800000001", "OK", 3},
+		{"13800000002", "isv.MOBILE_NUMBER_ILLEGAL", 1},
+		{"13800000003", "isp.SYSTEM_ERROR", DefaultRetryMaxAttempts},
+	}
+	for _, tt := range tests {
+		attempt.Store(0)
+		calls.Store(0)
+		_, result, err := client.SendContext(context.Background(), SendRequest{Recipients: Recipients(tt.phone), TemplateCode: "SMS_1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tea.StringValue(result.Code) != tt.code || result.Attempts != tt.attempts || calls.Load() != int64(tt.attempts) {
+			t.Errorf("%s: code %s, attempts %d, calls %d, want %s after %d", tt.phone, tea.StringValue(result.Code), result.Attempts, calls.Load(), tt.code, tt.attempts)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package alibaba
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"third_party_tool_library"
+
+	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// SendMode 发送方式
+type SendMode int
+
+const (
+	// SendModeSingle 单发：所有号码使用同一个签名和同一组模板参数（SendSms 接口）
+	SendModeSingle SendMode = iota
+	// SendModeBatch 批量发送：每个号码可以使用不同的签名和模板参数（SendBatchSms 接口）
+	SendModeBatch
+)
+
+const (
+	// MaxSingleRecipients 单发接口每次最多发送的号码个数
+	MaxSingleRecipients = 1000
+	// MaxBatchRecipients 批量发送接口每次最多发送的号码个数
+	MaxBatchRecipients = 100
+)
+
+// 发送接口每次最多发送的号码个数
+func (m SendMode) maxRecipients() int {
+	if m == SendModeBatch {
+		return MaxBatchRecipients
+	}
+	return MaxSingleRecipients
+}
+
+func (m SendMode) String() string {
+	switch m {
+	case SendModeSingle:
+		return "single"
+	case SendModeBatch:
+		return "batch"
+	default:
+		return fmt.Sprintf("SendMode(%d)", int(m))
+	}
+}
+
+// Recipient 短信接收对象
+type Recipient struct {
+	// 手机号码
+	PhoneNumber string
+	// 该号码使用的短信签名，为空时使用 SendRequest.SignName；仅批量发送时可用
+	SignName string
+	// 该号码使用的模板参数，为空时使用 SendRequest.TemplateParams；仅批量发送时可用
+	TemplateParams map[string]string
+}
+
+// Recipients 使用手机号码创建接收对象列表
+func Recipients(phoneNumbers ...string) []Recipient {
+	recipients := make([]Recipient, len(phoneNumbers))
+	for i, phoneNumber := range phoneNumbers {
+		recipients[i] = Recipient{PhoneNumber: phoneNumber}
+	}
+	return recipients
+}
+
+// SendRequest 短信发送请求
+/**
+ * 各字段会自动转换为发送接口需要的 JSON 字段，例如：
+ *	client.Send(alibaba.SendRequest{
+ *		Recipients:     alibaba.Recipients("13800000000"),
+ *		SignName:       "阿里云短信测试",
+ *		TemplateCode:   "SMS_153055065",
+ *		TemplateParams: map[string]string{"code": "1234"},
+ *	})
+ */
+type SendRequest struct {
+	// 发送方式，默认单发
+	Mode SendMode
+	// 接收对象，发送前会规范化并校验手机号码，见 NormalizePhoneNumber
+	Recipients []Recipient
+	// 短信签名，为空时依次使用命名模板的签名和客户端的默认签名
+	SignName string
+	// 短信模板 Code，与 Template 二选一
+	TemplateCode string
+	// 命名的短信模板（WithTemplate 注册），与 TemplateCode 二选一
+	Template string
+	// 模板参数
+	TemplateParams map[string]string
+	// 上行短信扩展码，可为空
+	SmsUpExtendCode string
+	// 外部流水扩展字段，可为空
+	OutId string
+	// 幂等键，可为空；指定时作为 OutId 发送，配置了 WithDedupStore 时，
+	// 相同幂等键在保留时间内只发送一次，重复的请求直接返回第一次的发送结果
+	IdempotencyKey string
+}
+
+// 解析后的短信发送请求，签名、模板 Code 已确定，Recipients 只包含号码合法的接收对象（号码已规范化）
+type resolvedSendRequest struct {
+	SendRequest
+	signNames []string
+	// 幂等记录使用的键，分片发送时为幂等键加分片序号
+	dedupKey string
+	// 接收对象在原请求中的下标，与 Recipients 一一对应
+	indexes []int
+	// 号码不合法的接收对象
+	rejected []rejectedRecipient
+}
+
+// 号码不合法、不会发送的接收对象
+type rejectedRecipient struct {
+	// 在原请求中的下标
+	index int
+	err   error
+}
+
+// 存在号码不合法的接收对象时返回错误
+func (r *resolvedSendRequest) rejectedError() error {
+	if len(r.rejected) == 0 {
+		return nil
+	}
+	first := r.rejected[0]
+	if len(r.rejected) == 1 {
+		return fmt.Errorf("第 %d 个接收对象：%w", first.index+1, first.err)
+	}
+	return fmt.Errorf("第 %d 个接收对象：%w（共 %d 个号码不合法，批量发送时会跳过不合法的号码）", first.index+1, first.err, len(r.rejected))
+}
+
+// 号码不合法的接收对象，用于在响应对象中报告
+func (r *resolvedSendRequest) rejectedRecipients(req SendRequest) []third_party_tool_library.RejectedRecipient {
+	rejected := make([]third_party_tool_library.RejectedRecipient, len(r.rejected))
+	for i, recipient := range r.rejected {
+		rejected[i] = third_party_tool_library.RejectedRecipient{
+			Index:       recipient.index,
+			PhoneNumber: req.Recipients[recipient.index].PhoneNumber,
+			Reason:      recipient.err.Error(),
+		}
+	}
+	return rejected
+}
+
+// 校验请求，并根据命名模板和默认签名确定每个号码的签名与模板 Code
+func (c *Client) resolveSendRequest(req SendRequest) (*resolvedSendRequest, error) {
+	if req.Mode != SendModeSingle && req.Mode != SendModeBatch {
+		return nil, fmt.Errorf("发送方式 %s 不合法", req.Mode)
+	}
+	if len(req.Recipients) == 0 {
+		return nil, errors.New("接收对象不能为空")
+	}
+	signName := req.SignName
+	if req.Template != "" {
+		if req.TemplateCode != "" {
+			return nil, errors.New("TemplateCode 与 Template 不能同时指定")
+		}
+		template, ok := c.templates[req.Template]
+		if !ok {
+			return nil, fmt.Errorf("%w：%s", ErrTemplateNotFound, req.Template)
+		}
+		req.TemplateCode = template.Code
+		signName = firstNonEmpty(signName, template.SignName)
+	}
+	if req.TemplateCode == "" {
+		return nil, errors.New("短信模板 Code 不能为空")
+	}
+	signName = firstNonEmpty(signName, c.signName)
+	if req.IdempotencyKey != "" {
+		if req.OutId != "" && req.OutId != req.IdempotencyKey {
+			return nil, errors.New("指定幂等键时 OutId 必须为空或与幂等键相同")
+		}
+		req.OutId = req.IdempotencyKey
+	}
+
+	resolved := &resolvedSendRequest{SendRequest: req, dedupKey: req.IdempotencyKey}
+	resolved.Recipients = make([]Recipient, 0, len(req.Recipients))
+	for i, recipient := range req.Recipients {
+		if req.Mode == SendModeSingle && (recipient.SignName != "" || recipient.TemplateParams != nil) {
+			return nil, errors.New("单发时不能为每个号码单独指定签名或模板参数，请使用 SendModeBatch")
+		}
+		recipientSignName := firstNonEmpty(recipient.SignName, signName)
+		if recipientSignName == "" {
+			return nil, errors.New("短信签名不能为空")
+		}
+		phoneNumber, err := NormalizePhoneNumber(recipient.PhoneNumber)
+		if err != nil {
+			resolved.rejected = append(resolved.rejected, rejectedRecipient{index: i, err: err})
+			continue
+		}
+		recipient.PhoneNumber = phoneNumber
+		resolved.Recipients = append(resolved.Recipients, recipient)
+		resolved.signNames = append(resolved.signNames, recipientSignName)
+		resolved.indexes = append(resolved.indexes, i)
+	}
+	return resolved, nil
+}
+
+// 转换为单发接口的请求
+func (r *resolvedSendRequest) sendSmsRequest() (*dysmsapi20170525.SendSmsRequest, error) {
+	phoneNumbers := make([]string, len(r.Recipients))
+	for i, recipient := range r.Recipients {
+		phoneNumbers[i] = recipient.PhoneNumber
+	}
+	req := &dysmsapi20170525.SendSmsRequest{
+		PhoneNumbers: tea.String(strings.Join(phoneNumbers, ",")),
+		SignName:     tea.String(r.signNames[0]),
+		TemplateCode: tea.String(r.TemplateCode),
+	}
+	if r.TemplateParams != nil {
+		templateParam, err := json.Marshal(r.TemplateParams)
+		if err != nil {
+			return nil, err
+		}
+		req.TemplateParam = tea.String(string(templateParam))
+	}
+	if r.SmsUpExtendCode != "" {
+		req.SmsUpExtendCode = tea.String(r.SmsUpExtendCode)
+	}
+	if r.OutId != "" {
+		req.OutId = tea.String(r.OutId)
+	}
+	return req, nil
+}
+
+// 转换为批量发送接口的请求，号码、签名、模板参数、扩展码按顺序一一对应
+func (r *resolvedSendRequest) sendBatchSmsRequest() (*dysmsapi20170525.SendBatchSmsRequest, error) {
+	phoneNumbers := make([]string, len(r.Recipients))
+	templateParams := make([]map[string]string, len(r.Recipients))
+	hasTemplateParams := false
+	for i, recipient := range r.Recipients {
+		phoneNumbers[i] = recipient.PhoneNumber
+		templateParams[i] = recipient.TemplateParams
+		if templateParams[i] == nil {
+			templateParams[i] = r.TemplateParams
+		}
+		if templateParams[i] != nil {
+			hasTemplateParams = true
+		} else {
+			templateParams[i] = map[string]string{}
+		}
+	}
+	req := &dysmsapi20170525.SendBatchSmsRequest{TemplateCode: tea.String(r.TemplateCode)}
+	fields := []struct {
+		target **string
+		value  interface{}
+		skip   bool
+	}{
+		{&req.PhoneNumberJson, phoneNumbers, false},
+		{&req.SignNameJson, r.signNames, false},
+		{&req.TemplateParamJson, templateParams, !hasTemplateParams},
+		{&req.SmsUpExtendCodeJson, repeatString(r.SmsUpExtendCode, len(r.Recipients)), r.SmsUpExtendCode == ""},
+	}
+	for _, field := range fields {
+		if field.skip {
+			continue
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		*field.target = tea.String(string(value))
+	}
+	if r.OutId != "" {
+		req.OutId = tea.String(r.OutId)
+	}
+	return req, nil
+}
+
+func repeatString(value string, count int) []string {
+	values := make([]string, count)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+// parseSendRequest
+/** 将 SmsSend 的 JSON 字符串参数解析为 SendRequest
+ * 单发时 phoneNumbers 为逗号分隔的号码，templateParam 为 JSON 对象；
+ * 批量发送时 phoneNumbers、signName、templateParam 都是 JSON 数组，一一对应
+ * 模板参数中的数字、布尔值会转换为字符串
+ */
+func parseSendRequest(phoneNumbers, signName, templateCode, templateParam string, isBatchSend bool) (SendRequest, error) {
+	req := SendRequest{TemplateCode: templateCode}
+	if !isBatchSend {
+		req.SignName = signName
+		for _, phoneNumber := range strings.Split(phoneNumbers, ",") {
+			if phoneNumber = strings.TrimSpace(phoneNumber); phoneNumber != "" {
+				req.Recipients = append(req.Recipients, Recipient{PhoneNumber: phoneNumber})
+			}
+		}
+		if strings.TrimSpace(templateParam) != "" {
+			params, err := parseTemplateParams([]byte(templateParam))
+			if err != nil {
+				return req, fmt.Errorf("模板参数必须是 JSON 对象：%w", err)
+			}
+			req.TemplateParams = params
+		}
+		return req, nil
+	}
+
+	req.Mode = SendModeBatch
+	var phones []string
+	if err := json.Unmarshal([]byte(phoneNumbers), &phones); err != nil {
+		return req, fmt.Errorf("批量发送的手机号码必须是 JSON 数组：%w", err)
+	}
+	req.Recipients = Recipients(phones...)
+	if signName != "" {
+		var signNames []string
+		if err := json.Unmarshal([]byte(signName), &signNames); err != nil {
+			return req, fmt.Errorf("批量发送的短信签名必须是 JSON 数组：%w", err)
+		}
+		if len(signNames) != len(phones) {
+			return req, fmt.Errorf("短信签名个数（%d）与手机号码个数（%d）不一致", len(signNames), len(phones))
+		}
+		for i := range req.Recipients {
+			req.Recipients[i].SignName = signNames[i]
+		}
+	}
+	if strings.TrimSpace(templateParam) != "" {
+		var rawParams []json.RawMessage
+		if err := json.Unmarshal([]byte(templateParam), &rawParams); err != nil {
+			return req, fmt.Errorf("批量发送的模板参数必须是 JSON 数组：%w", err)
+		}
+		if len(rawParams) != len(phones) {
+			return req, fmt.Errorf("模板参数个数（%d）与手机号码个数（%d）不一致", len(rawParams), len(phones))
+		}
+		for i, raw := range rawParams {
+			params, err := parseTemplateParams(raw)
+			if err != nil {
+				return req, fmt.Errorf("第 %d 组模板参数必须是 JSON 对象：%w", i+1, err)
+			}
+			req.Recipients[i].TemplateParams = params
+		}
+	}
+	return req, nil
+}
+
+// 解析模板参数 JSON 对象，非字符串的值保留其 JSON 文本
+func parseTemplateParams(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	params := make(map[string]string, len(raw))
+	for key, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			params[key] = text
+			continue
+		}
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			params[key] = ""
+			continue
+		}
+		params[key] = string(bytes.TrimSpace(value))
+	}
+	return params, nil
+}
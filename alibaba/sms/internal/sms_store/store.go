@@ -0,0 +1,102 @@
+package sms_store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Schema 按时间索引的记录的存储方式，发件箱的消息和定时任务共用同一套存储实现
+type Schema[T any] struct {
+	// 记录的名称，用于错误信息，例如 发件箱消息、定时任务
+	Name string
+	// 保存记录的 bucket
+	Items []byte
+	// 时间索引的 bucket，键为 8 字节的时间（UnixNano，大端序）加记录 ID，值为空
+	Index []byte
+	// 记录的 ID
+	ID func(T) string
+	// 记录等待处理时返回处理时间和 true，只有等待处理的记录进入时间索引
+	Due func(T) (time.Time, bool)
+	// 相同 ID 的记录已存在时返回的错误
+	ErrExists error
+	// 记录不存在时返回的错误
+	ErrNotFound error
+}
+
+// MemoryStore 内存中的存储，进程退出后记录丢失，仅用于测试
+type MemoryStore[T any] struct {
+	schema Schema[T]
+	mu     sync.Mutex
+	items  map[string]T
+}
+
+// NewMemoryStore 创建内存中的存储
+func NewMemoryStore[T any](schema Schema[T]) *MemoryStore[T] {
+	return &MemoryStore[T]{schema: schema, items: make(map[string]T)}
+}
+
+func (s *MemoryStore[T]) Create(item T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.schema.ID(item)
+	if _, ok := s.items[id]; ok {
+		return fmt.Errorf("%w：%s", s.schema.ErrExists, id)
+	}
+	s.items[id] = item
+	return nil
+}
+
+func (s *MemoryStore[T]) Update(item T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.schema.ID(item)
+	if _, ok := s.items[id]; !ok {
+		return fmt.Errorf("%w：%s", s.schema.ErrNotFound, id)
+	}
+	s.items[id] = item
+	return nil
+}
+
+func (s *MemoryStore[T]) Get(id string) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[id]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w：%s", s.schema.ErrNotFound, id)
+	}
+	return item, nil
+}
+
+// Due 查询等待处理且到了处理时间的记录，按处理时间升序，最多 limit 条
+func (s *MemoryStore[T]) Due(now time.Time, limit int) ([]T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type dueItem struct {
+		item T
+		at   time.Time
+	}
+	var due []dueItem
+	for _, item := range s.items {
+		if at, ok := s.schema.Due(item); ok && !at.After(now) {
+			due = append(due, dueItem{item: item, at: at})
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].at.Before(due[j].at)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	items := make([]T, len(due))
+	for i, d := range due {
+		items[i] = d.item
+	}
+	return items, nil
+}
+
+func (s *MemoryStore[T]) Close() error {
+	return nil
+}
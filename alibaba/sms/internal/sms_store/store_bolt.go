@@ -0,0 +1,157 @@
+package sms_store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltStore 基于 bbolt 文件的存储，同一个文件只能被一个进程打开
+type BoltStore[T any] struct {
+	schema Schema[T]
+	db     *bolt.DB
+}
+
+// OpenBoltStore
+/** 打开存储文件，不存在时创建
+ * @param path 文件路径
+ * @param schema 记录的存储方式
+ * @return *BoltStore 存储，使用完毕后调用 Close
+ * @return error 文件已被其他进程打开时等待 1 秒后返回超时错误
+ */
+func OpenBoltStore[T any](path string, schema Schema[T]) (*BoltStore[T], error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(schema.Items); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(schema.Index)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &BoltStore[T]{schema: schema, db: db}, nil
+}
+
+func indexKey(at time.Time, id string) []byte {
+	key := make([]byte, 8, 8+len(id))
+	binary.BigEndian.PutUint64(key, uint64(at.UnixNano()))
+	return append(key, id...)
+}
+
+// 写入记录并维护索引，old 为更新前的记录，新建时为 nil
+func (s *BoltStore[T]) put(tx *bolt.Tx, item T, old *T) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	id := s.schema.ID(item)
+	index := tx.Bucket(s.schema.Index)
+	if old != nil {
+		if at, ok := s.schema.Due(*old); ok {
+			if err := index.Delete(indexKey(at, id)); err != nil {
+				return err
+			}
+		}
+	}
+	if at, ok := s.schema.Due(item); ok {
+		if err := index.Put(indexKey(at, id), nil); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(s.schema.Items).Put([]byte(id), data)
+}
+
+func (s *BoltStore[T]) get(tx *bolt.Tx, id string) (*T, error) {
+	data := tx.Bucket(s.schema.Items).Get([]byte(id))
+	if data == nil {
+		return nil, nil
+	}
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("解析%s %s 失败：%w", s.schema.Name, id, err)
+	}
+	return &item, nil
+}
+
+func (s *BoltStore[T]) Create(item T) error {
+	id := s.schema.ID(item)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		old, err := s.get(tx, id)
+		if err != nil {
+			return err
+		}
+		if old != nil {
+			return fmt.Errorf("%w：%s", s.schema.ErrExists, id)
+		}
+		return s.put(tx, item, nil)
+	})
+}
+
+func (s *BoltStore[T]) Update(item T) error {
+	id := s.schema.ID(item)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		old, err := s.get(tx, id)
+		if err != nil {
+			return err
+		}
+		if old == nil {
+			return fmt.Errorf("%w：%s", s.schema.ErrNotFound, id)
+		}
+		return s.put(tx, item, old)
+	})
+}
+
+func (s *BoltStore[T]) Get(id string) (T, error) {
+	var item *T
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		item, err = s.get(tx, id)
+		return err
+	})
+	var zero T
+	if err != nil {
+		return zero, err
+	}
+	if item == nil {
+		return zero, fmt.Errorf("%w：%s", s.schema.ErrNotFound, id)
+	}
+	return *item, nil
+}
+
+// Due 查询等待处理且到了处理时间的记录，按处理时间升序，最多 limit 条
+func (s *BoltStore[T]) Due(now time.Time, limit int) ([]T, error) {
+	var due []T
+	end := make([]byte, 8)
+	binary.BigEndian.PutUint64(end, uint64(now.UnixNano()))
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(s.schema.Index).Cursor()
+		for key, _ := cursor.First(); key != nil && len(due) < limit; key, _ = cursor.Next() {
+			if bytes.Compare(key[:8], end) > 0 {
+				break
+			}
+			item, err := s.get(tx, string(key[8:]))
+			if err != nil {
+				return err
+			}
+			if item != nil {
+				due = append(due, *item)
+			}
+		}
+		return nil
+	})
+	return due, err
+}
+
+func (s *BoltStore[T]) Close() error {
+	return s.db.Close()
+}
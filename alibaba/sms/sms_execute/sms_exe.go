@@ -1,18 +1,136 @@
 package sms_execute
 
 import (
+	"context"
+	"sync"
+
 	"third_party_tool_library"
 	"third_party_tool_library/alibaba"
+)
 
-	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
-	util "github.com/alibabacloud-go/tea-utils/v2/service"
-	"github.com/alibabacloud-go/tea/tea"
+var (
+	settingsMu  sync.RWMutex
+	dedupStore  alibaba.DedupStore = alibaba.NewMemoryDedupStore()
+	flowLimiter *alibaba.FlowLimiter
+	breaker     *alibaba.CircuitBreaker
 )
 
+// SetDedupStore
+/** 指定本包发送短信时使用的幂等记录存储，默认为进程内的 alibaba.MemoryDedupStore
+ * 多个进程或实例之间需要去重时，使用基于 Redis 或数据库实现的 alibaba.DedupStore
+ * @param store 幂等记录存储，为 nil 时不去重，IdempotencyKey 只作为 OutId 使用
+ */
+func SetDedupStore(store alibaba.DedupStore) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	dedupStore = store
+}
+
+// SetFlowLimiter
+/** 指定本包发送短信时使用的本地限流器，默认不限流
+ * 超过限流时不发送，返回 429 和 *alibaba.RateLimitError，其中 RetryAfter 为需要等待的时间
+ * @param limiter 限流器，见 alibaba.NewFlowLimiter，为 nil 时不限流
+ */
+func SetFlowLimiter(limiter *alibaba.FlowLimiter) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	flowLimiter = limiter
+}
+
+// SetCircuitBreaker
+/** 指定本包发送短信时使用的熔断器，默认不熔断
+ * 短信接口故障时，熔断期间的发送直接返回 503 和 alibaba.ErrCircuitOpen，不再等待超时
+ * @param circuitBreaker 熔断器，见 alibaba.NewCircuitBreaker，为 nil 时不熔断；状态见 CircuitBreaker.Stats
+ */
+func SetCircuitBreaker(circuitBreaker *alibaba.CircuitBreaker) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	breaker = circuitBreaker
+}
+
+// 创建发送短信使用的客户端，每次调用都重新创建，幂等记录、限流额度和熔断状态在调用之间共享
+func newSendClient(accessKeyId, accessKeySecret string) (*alibaba.Client, error) {
+	settingsMu.RLock()
+	var opts []alibaba.Option
+	if dedupStore != nil {
+		opts = append(opts, alibaba.WithDedupStore(dedupStore, 0))
+	}
+	if flowLimiter != nil {
+		opts = append(opts, alibaba.WithFlowControl(flowLimiter))
+	}
+	if breaker != nil {
+		opts = append(opts, alibaba.WithCircuitBreaker(breaker))
+	}
+	settingsMu.RUnlock()
+	return alibaba.NewClient(accessKeyId, accessKeySecret, opts...)
+}
+
+// Send 短信发送
+/**
+ * 使用类型化的发送请求，手机号码、签名、模板参数会自动转换为发送接口需要的 JSON 字段
+ * 手机号码会先规范化并校验（见 alibaba.NormalizePhoneNumber），单发时存在不合法的号码不发送，返回 400；
+ * 批量发送时跳过不合法的号码，其余号码照常发送，跳过的号码见 ResponseResult.Rejected
+ * 指定 req.IdempotencyKey 时，超时后使用相同幂等键重试不会重复发送，见 SetDedupStore
+ * 每次调用都会重新创建客户端，频繁发送时请使用 alibaba.NewClient 创建的客户端对象
+ * @param accessKeyId 访问秘钥ID
+ * @param accessKeySecret 访问秘钥凭证
+ * @param req 发送请求
+ * @return int32 接口响应编码，包含参数检测的错误编码（可以判断该编码是否为200）
+ * @return third_party_tool_library.ResponseResult 响应对象（第三方返回的响应信息都在里面，包含业务错误）
+ * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
+ */
+func Send(accessKeyId, accessKeySecret string, req alibaba.SendRequest) (int32, third_party_tool_library.ResponseResult, error) {
+	return SendContext(context.Background(), accessKeyId, accessKeySecret, req)
+}
+
+// SendContext
+/** 短信发送，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 Send
+ */
+func SendContext(ctx context.Context, accessKeyId, accessKeySecret string, req alibaba.SendRequest) (int32, third_party_tool_library.ResponseResult, error) {
+	client, err := newSendClient(accessKeyId, accessKeySecret)
+	if err != nil {
+		return 500, third_party_tool_library.ResponseResult{}, err
+	}
+	return client.SendContext(ctx, req)
+}
+
+// SendBatch 分片发送短信
+/**
+ * 接收对象个数不受限制，按发送接口的上限拆分为多个分片并发发送，部分分片失败不影响其他分片
+ * 号码不合法的接收对象不会发送，在 BatchResult.Recipients 中单独报告
+ * 每次调用都会重新创建客户端，频繁发送时请使用 alibaba.NewClient 创建的客户端对象
+ * @param accessKeyId 访问秘钥ID
+ * @param accessKeySecret 访问秘钥凭证
+ * @param req 发送请求
+ * @param concurrency 同时发送的分片数，小于等于 0 时使用 alibaba.DefaultBatchConcurrency
+ * @return int32 接口响应编码：全部成功为 200，部分成功为 207，参数不合法时为 400
+ * @return alibaba.BatchResult 每个分片和每个接收对象的发送结果
+ * @return error 错误响应对象
+ */
+func SendBatch(accessKeyId, accessKeySecret string, req alibaba.SendRequest, concurrency int) (int32, alibaba.BatchResult, error) {
+	return SendBatchContext(context.Background(), accessKeyId, accessKeySecret, req, concurrency)
+}
+
+// SendBatchContext
+/** 分片发送短信，ctx 被取消后尚未发送的分片不再发送
+ * 参数与返回值同 SendBatch
+ */
+func SendBatchContext(ctx context.Context, accessKeyId, accessKeySecret string, req alibaba.SendRequest, concurrency int) (int32, alibaba.BatchResult, error) {
+	client, err := newSendClient(accessKeyId, accessKeySecret)
+	if err != nil {
+		return 500, alibaba.BatchResult{}, err
+	}
+	return client.SendBatchContext(ctx, req, concurrency)
+}
+
 // SmsSend 短信发送
 /**
  * 批量发送使用的是同一个短信模板，手机号码、短信签名、模板中的参数、都是json串的方式，都是一一对应的，例如：phoneNumbers{"139xxx1","136xxx1"},signName{"xxx通知","xxx短信"}
- * 单个短信发送是每个号码都将使用不同的短信模板和签名
+ * 单个短信发送时所有号码（逗号分隔）使用同一个签名和模板参数
+ * 手机号码会先规范化并校验，单发时存在不合法的号码不发送，返回 400；批量发送时跳过不合法的号码，见 ResponseResult.Rejected
+ * 新代码建议使用类型化的 Send，需要在超时重试时避免重复发送的，使用 Send 并指定 IdempotencyKey
+ * 每次调用都会重新创建客户端，频繁发送时请使用 alibaba.NewClient 创建的客户端对象
  * 错误码列表: https://help.aliyun.com/zh/sms/developer-reference/api-error-codes
  * @param phoneNumbers 接收对象的手机号码
  * @param signName 短信签名名称
@@ -24,51 +142,103 @@ import (
  * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
  */
 func SmsSend(accessKeyId, accessKeySecret, phoneNumbers, signName, templateCode, templateParam string, isBatchSend bool) (int32, third_party_tool_library.ResponseResult, error) {
+	return SmsSendContext(context.Background(), accessKeyId, accessKeySecret, phoneNumbers, signName, templateCode, templateParam, isBatchSend)
+}
+
+// SmsSendContext
+/** 短信发送，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 SmsSend
+ */
+func SmsSendContext(ctx context.Context, accessKeyId, accessKeySecret, phoneNumbers, signName, templateCode, templateParam string, isBatchSend bool) (int32, third_party_tool_library.ResponseResult, error) {
 	var statusCode int32
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := newSendClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return statusCode, third_party_tool_library.ResponseResult{}, _err
 	}
-	// 创建并初始化发送信息对象
-	respMsg := third_party_tool_library.ResponseResult{}
-	if isBatchSend {
-		sendBatchSmsRequest := &dysmsapi20170525.SendBatchSmsRequest{
-			PhoneNumberJson:   tea.String(phoneNumbers),
-			SignNameJson:      tea.String(signName),
-			TemplateCode:      tea.String(templateCode),
-			TemplateParamJson: tea.String(templateParam),
-		}
-		statusCode, respMsg, _err = batchSmsSend(client, sendBatchSmsRequest, &util.RuntimeOptions{})
-	} else {
-		sendSmsRequest := &dysmsapi20170525.SendSmsRequest{
-			PhoneNumbers:  tea.String(phoneNumbers),
-			SignName:      tea.String(signName),
-			TemplateCode:  tea.String(templateCode),
-			TemplateParam: tea.String(templateParam),
-		}
-		statusCode, respMsg, _err = singleSmsSend(client, sendSmsRequest, &util.RuntimeOptions{})
-	}
-	if _err != nil {
-		return statusCode, third_party_tool_library.ResponseResult{}, _err
+	return client.SmsSendContext(ctx, phoneNumbers, signName, templateCode, templateParam, isBatchSend)
+}
+
+// SendGlobe 发送国际/港澳台短信
+/**
+ * 使用国际站的 SendMessageToGlobe（短信内容）或 SendMessageWithTemplate（短信模板）接口，号码必须带国家码
+ * 每次调用都会重新创建客户端，频繁发送时请使用 alibaba.NewClient 创建的客户端对象
+ * @param accessKeyId 访问秘钥ID
+ * @param accessKeySecret 访问秘钥凭证
+ * @param req 发送请求
+ * @return int32 接口响应编码，包含参数检测的错误编码（可以判断该编码是否为200）
+ * @return third_party_tool_library.ResponseResult 响应对象，Code 为接口返回的 ResponseCode，成功时为 OK
+ * @return alibaba.GlobeResult 发送结果，包含消息 ID 和号码所属的国家、运营商
+ * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
+ */
+func SendGlobe(accessKeyId, accessKeySecret string, req alibaba.GlobeSendRequest) (int32, third_party_tool_library.ResponseResult, alibaba.GlobeResult, error) {
+	return SendGlobeContext(context.Background(), accessKeyId, accessKeySecret, req)
+}
+
+// SendGlobeContext
+/** 发送国际/港澳台短信，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 SendGlobe
+ */
+func SendGlobeContext(ctx context.Context, accessKeyId, accessKeySecret string, req alibaba.GlobeSendRequest) (int32, third_party_tool_library.ResponseResult, alibaba.GlobeResult, error) {
+	client, err := newSendClient(accessKeyId, accessKeySecret)
+	if err != nil {
+		return 500, third_party_tool_library.ResponseResult{}, alibaba.GlobeResult{}, err
 	}
-	return statusCode, respMsg, nil
+	return client.SendGlobeContext(ctx, req)
 }
 
-// 发送单个短信
-func singleSmsSend(client *dysmsapi20170525.Client, req *dysmsapi20170525.SendSmsRequest, runtime *util.RuntimeOptions) (int32, third_party_tool_library.ResponseResult, error) {
-	result, err := client.SendSmsWithOptions(req, runtime)
+// QuerySendDetails 查询短信发送记录和发送状态
+/**
+ * 按手机号码、发送日期和发送回执 ID（BizId）查询，自动翻页返回所有记录
+ * 每次调用都会重新创建客户端，频繁查询时请使用 alibaba.NewClient 创建的客户端对象
+ * @param accessKeyId 访问秘钥ID
+ * @param accessKeySecret 访问秘钥凭证
+ * @param query 查询条件
+ * @return int32 接口响应编码，包含参数检测的错误编码（可以判断该编码是否为200）
+ * @return third_party_tool_library.ResponseResult 响应对象（第三方返回的响应信息都在里面，包含业务错误）
+ * @return []alibaba.SendDetail 发送记录
+ * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
+ */
+func QuerySendDetails(accessKeyId, accessKeySecret string, query alibaba.SendDetailsQuery) (int32, third_party_tool_library.ResponseResult, []alibaba.SendDetail, error) {
+	return QuerySendDetailsContext(context.Background(), accessKeyId, accessKeySecret, query)
+}
+
+// QuerySendDetailsContext
+/** 查询短信发送记录和发送状态，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 QuerySendDetails
+ */
+func QuerySendDetailsContext(ctx context.Context, accessKeyId, accessKeySecret string, query alibaba.SendDetailsQuery) (int32, third_party_tool_library.ResponseResult, []alibaba.SendDetail, error) {
+	client, err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, err
+		return 500, third_party_tool_library.ResponseResult{}, nil, err
 	}
-	return tea.Int32Value(result.StatusCode), third_party_tool_library.NewResult(result.Body.Code, result.Body.Message), nil
+	return client.QuerySendDetailsContext(ctx, query)
 }
 
-// 批量发送短信
-func batchSmsSend(client *dysmsapi20170525.Client, req *dysmsapi20170525.SendBatchSmsRequest, runtime *util.RuntimeOptions) (int32, third_party_tool_library.ResponseResult, error) {
-	result, err := client.SendBatchSmsWithOptions(req, runtime)
+// QuerySendStatistics 查询短信发送统计
+/**
+ * 按日期范围、发送范围（国内或国际/港澳台）和短信签名查询，自动翻页并汇总每天的发送数量和合计
+ * 每次调用都会重新创建客户端，频繁查询时请使用 alibaba.NewClient 创建的客户端对象
+ * @param accessKeyId 访问秘钥ID
+ * @param accessKeySecret 访问秘钥凭证
+ * @param query 查询条件
+ * @return int32 接口响应编码，包含参数检测的错误编码（可以判断该编码是否为200）
+ * @return third_party_tool_library.ResponseResult 响应对象（第三方返回的响应信息都在里面，包含业务错误）
+ * @return alibaba.SendStatistics 发送统计
+ * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
+ */
+func QuerySendStatistics(accessKeyId, accessKeySecret string, query alibaba.SendStatisticsQuery) (int32, third_party_tool_library.ResponseResult, alibaba.SendStatistics, error) {
+	return QuerySendStatisticsContext(context.Background(), accessKeyId, accessKeySecret, query)
+}
+
+// QuerySendStatisticsContext
+/** 查询短信发送统计，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 QuerySendStatistics
+ */
+func QuerySendStatisticsContext(ctx context.Context, accessKeyId, accessKeySecret string, query alibaba.SendStatisticsQuery) (int32, third_party_tool_library.ResponseResult, alibaba.SendStatistics, error) {
+	client, err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, err
+		return 500, third_party_tool_library.ResponseResult{}, alibaba.SendStatistics{}, err
 	}
-	return tea.Int32Value(result.StatusCode), third_party_tool_library.NewResult(result.Body.Code, result.Body.Message), nil
+	return client.QuerySendStatisticsContext(ctx, query)
 }
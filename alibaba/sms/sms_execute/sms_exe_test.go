@@ -0,0 +1,21 @@
+package sms_execute
+
+import (
+	"testing"
+
+	"third_party_tool_library/alibaba"
+)
+
+func TestSetDedupStoreNil(t *testing.T) {
+	defer SetDedupStore(alibaba.NewMemoryDedupStore())
+
+	// 为 nil 时不去重，仍然可以创建客户端
+	SetDedupStore(nil)
+	if _, err := newSendClient("id", "secret"); err != nil {
+		t.Fatalf("newSendClient() error = %v", err)
+	}
+	SetDedupStore(alibaba.NewMemoryDedupStore())
+	if _, err := newSendClient("id", "secret"); err != nil {
+		t.Fatalf("newSendClient() error = %v", err)
+	}
+}
@@ -0,0 +1,446 @@
+package sms_outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"third_party_tool_library/alibaba"
+	"third_party_tool_library/alibaba/sms/sms_queue"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+const (
+	// DefaultWorkers 默认同时发送的消息数
+	DefaultWorkers = 4
+	// DefaultBatchSize 每次从存储中取出的最多消息数
+	DefaultBatchSize = 100
+	// DefaultPollInterval 默认的轮询间隔
+	DefaultPollInterval = time.Second
+	// DefaultMaxAttempts 默认最多发送次数，超过后进入死信
+	DefaultMaxAttempts = 5
+	// DefaultRetryDelay 默认的第一次重试等待时间，之后每次翻倍
+	DefaultRetryDelay = 10 * time.Second
+	// DefaultMaxRetryDelay 默认的最长重试等待时间
+	DefaultMaxRetryDelay = 10 * time.Minute
+)
+
+// ErrClosed 发件箱已关闭
+var ErrClosed = errors.New("发件箱已关闭")
+
+// Verifier 确认结果未知的消息是否已经发出，用于重新发送前去重
+/**
+ * @return sent 已经发出时为 true，此时消息直接标记为已发送
+ * @return bizId 已经发出时的发送回执 ID，可为空
+ * @return err 无法确认时返回错误，消息稍后重试确认
+ */
+type Verifier func(ctx context.Context, msg Message) (sent bool, bizId string, err error)
+
+// DetailsVerifier
+/** 通过 QuerySendDetails 按 OutId 确认消息是否已经发出：依次查询每个接收号码在最近一次开始发送（Message.LastAttemptAt）当天的记录，
+ * 任一号码存在 OutId 与消息 ID 相同的记录时认为已发出；号码不合法的接收对象不会发送，不查询；
+ * 部分号码查询失败且其他号码都没有记录时返回查询失败的错误，稍后重试确认；发送记录可能有延迟，刚发出的短信可能查不到
+ * @param client 短信客户端
+ */
+func DetailsVerifier(client *alibaba.Client) Verifier {
+	return func(ctx context.Context, msg Message) (bool, string, error) {
+		if msg.LastAttemptAt.IsZero() {
+			return false, "", nil
+		}
+		var queryErr error
+		for _, recipient := range msg.Request.Recipients {
+			if _, err := alibaba.NormalizePhoneNumber(recipient.PhoneNumber); err != nil {
+				continue
+			}
+			statusCode, resp, details, err := client.QuerySendDetailsContext(ctx, alibaba.SendDetailsQuery{
+				PhoneNumber: recipient.PhoneNumber,
+				SendDate:    msg.LastAttemptAt,
+			})
+			if err == nil && tea.StringValue(resp.Code) != "OK" {
+				err = fmt.Errorf("查询发送记录失败（%d）：%s", statusCode, tea.StringValue(resp.Message))
+			}
+			if err != nil {
+				queryErr = err
+				continue
+			}
+			for _, detail := range details {
+				if detail.OutId == msg.ID {
+					return true, "", nil
+				}
+			}
+		}
+		return false, "", queryErr
+	}
+}
+
+// Config 发件箱配置
+type Config struct {
+	// 发送函数，例如 sms_queue.ExecuteSender(accessKeyId, accessKeySecret)
+	Sender sms_queue.Sender
+	// 重新发送结果未知的消息前确认是否已经发出，为 nil 时直接重新发送，见 DetailsVerifier
+	Verifier Verifier
+	// 同时发送的消息数，为 0 时使用 DefaultWorkers
+	Workers int
+	// 每次从存储中取出的最多消息数，为 0 时使用 DefaultBatchSize
+	BatchSize int
+	// 轮询间隔，为 0 时使用 DefaultPollInterval；Submit 写入的消息会立即发送，不等待轮询
+	PollInterval time.Duration
+	// 最多发送次数，超过后进入死信，为 0 时使用 DefaultMaxAttempts
+	MaxAttempts int
+	// 第一次重试的等待时间，之后每次翻倍，为 0 时使用 DefaultRetryDelay
+	RetryDelay time.Duration
+	// 最长重试等待时间，为 0 时使用 DefaultMaxRetryDelay
+	MaxRetryDelay time.Duration
+	// 客户端幂等键的保留时间，与 alibaba.WithDedupStore 的 ttl 相同，为 0 时使用 alibaba.DefaultDedupTTL；
+	// 发送返回 alibaba.ErrSendInProgress 且没有配置 Verifier 时，等待该时间、幂等键过期后再重新发送
+	DedupTTL time.Duration
+	// 后台发送时读写存储出错的回调，可用于记录日志，可为空
+	OnError func(err error)
+}
+
+// Outbox 持久化的短信发件箱
+/**
+ * 消息先写入存储再由后台协程发送，进程崩溃后重启会继续发送未完成的消息（至少一次）：
+ * 每次发送前先记录发送次数，发送结果未知的消息重新发送时使用相同的消息 ID 作为 OutId 和幂等键，
+ * 配置 Verifier 或客户端的持久化 alibaba.DedupStore 可以避免重复发送；
+ * 客户端的幂等记录存储必须是持久化的（例如基于 Redis 或数据库的实现），或者不配置：
+ * alibaba.MemoryDedupStore 在进程重启后丢失记录，无法为重启后重新发送的消息去重，
+ * 幂等键被占用（alibaba.ErrSendInProgress）时不计入发送次数，通过 Verifier 确认或等待幂等键过期后重新发送，例如：
+ *	store, _ := sms_outbox.OpenBoltStore("/var/lib/app/sms_outbox.db")
+ *	outbox, _ := sms_outbox.New(store, sms_outbox.Config{Sender: sms_queue.ExecuteSender(accessKeyId, accessKeySecret)})
+ *	id, err := outbox.Submit(req)
+ *	...
+ *	msg, err := outbox.Get(id)
+ */
+type Outbox struct {
+	store  Store
+	config Config
+
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+	// 发送使用的 ctx，关闭超时后取消
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// New
+/** 创建发件箱并启动后台发送协程
+ * @param store 发件箱存储，例如 OpenBoltStore 的返回值，由调用方关闭
+ * @param config 发件箱配置，Sender 不能为空
+ * @return *Outbox 发件箱
+ * @return error 配置不合法时返回错误
+ */
+func New(store Store, config Config) (*Outbox, error) {
+	if store == nil {
+		return nil, errors.New("发件箱存储不能为空")
+	}
+	if config.Sender == nil {
+		return nil, errors.New("发送函数不能为空")
+	}
+	if config.Workers < 0 || config.BatchSize < 0 || config.PollInterval < 0 || config.MaxAttempts < 0 || config.RetryDelay < 0 || config.MaxRetryDelay < 0 || config.DedupTTL < 0 {
+		return nil, errors.New("发件箱配置不能小于 0")
+	}
+	if config.Workers == 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = DefaultRetryDelay
+	}
+	if config.MaxRetryDelay == 0 {
+		config.MaxRetryDelay = DefaultMaxRetryDelay
+	}
+	if config.DedupTTL == 0 {
+		config.DedupTTL = alibaba.DefaultDedupTTL
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	o := &Outbox{
+		store:   store,
+		config:  config,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	go o.run()
+	return o, nil
+}
+
+// Submit
+/** 写入待发送的短信，写入成功后即使进程崩溃也会在重启后发送
+ * 消息 ID 依次使用 req.IdempotencyKey、req.OutId，都为空时随机生成；
+ * 相同 ID 的消息已存在时不重复写入，直接返回该 ID
+ * @param req 发送请求
+ * @return string 消息 ID，用于 Get 查询状态
+ * @return error 写入失败时返回错误
+ */
+func (o *Outbox) Submit(req alibaba.SendRequest) (string, error) {
+	select {
+	case <-o.stop:
+		return "", ErrClosed
+	default:
+	}
+	if len(req.Recipients) == 0 {
+		return "", errors.New("接收对象不能为空")
+	}
+	id := req.IdempotencyKey
+	if id == "" {
+		id = req.OutId
+	}
+	if id == "" {
+		var err error
+		if id, err = newMessageId(); err != nil {
+			return "", err
+		}
+	}
+	if req.OutId != "" && req.OutId != id {
+		return "", errors.New("指定幂等键时 OutId 必须为空或与幂等键相同")
+	}
+	req.IdempotencyKey = id
+	now := time.Now()
+	err := o.store.Create(Message{
+		ID:            id,
+		Request:       req,
+		Status:        StatusPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		NextAttemptAt: now,
+	})
+	if err != nil && !errors.Is(err, ErrExists) {
+		return "", err
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return id, nil
+}
+
+// Get
+/** 按 ID 查询消息和发送状态
+ * @param id 消息 ID
+ * @return Message 消息
+ * @return error 消息不存在时返回 ErrNotFound
+ */
+func (o *Outbox) Get(id string) (Message, error) {
+	return o.store.Get(id)
+}
+
+// Close
+/** 停止后台发送，等待正在发送的消息完成；未发送的消息保留在存储中，下次启动后继续发送
+ * 不会关闭存储，请在之后调用 Store.Close
+ * @param ctx 等待的截止时间，超过后取消发送使用的 ctx、不再等待正在发送的消息，这些消息可能已经发出，结果记为未知
+ * @return error 超过截止时间时返回包装后的 ctx.Err()
+ */
+func (o *Outbox) Close(ctx context.Context) error {
+	o.closeOnce.Do(func() {
+		close(o.stop)
+	})
+	select {
+	case <-o.stopped:
+		o.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+	o.cancel()
+	<-o.stopped
+	return fmt.Errorf("发件箱关闭超时，已停止等待正在发送的消息：%w", ctx.Err())
+}
+
+func (o *Outbox) run() {
+	defer close(o.stopped)
+	ticker := time.NewTicker(o.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		// 取满一批时说明还有到期的消息，继续发送
+		for o.dispatchDue() == o.config.BatchSize {
+			select {
+			case <-o.stop:
+				return
+			default:
+			}
+		}
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// 发送一批到期的消息，返回取出的消息数
+func (o *Outbox) dispatchDue() int {
+	messages, err := o.store.Due(time.Now(), o.config.BatchSize)
+	if err != nil {
+		o.reportError(fmt.Errorf("查询待发送的消息失败：%w", err))
+		return 0
+	}
+	sem := make(chan struct{}, o.config.Workers)
+	var wg sync.WaitGroup
+	for _, msg := range messages {
+		select {
+		case <-o.stop:
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(msg Message) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				if err := o.dispatch(msg); err != nil {
+					o.reportError(fmt.Errorf("更新消息 %s 失败：%w", msg.ID, err))
+				}
+			}(msg)
+			continue
+		}
+		break
+	}
+	wg.Wait()
+	return len(messages)
+}
+
+func (o *Outbox) reportError(err error) {
+	if o.config.OnError != nil {
+		o.config.OnError(err)
+	}
+}
+
+// 发送一条消息并记录结果
+func (o *Outbox) dispatch(msg Message) error {
+	if msg.Unconfirmed && o.config.Verifier != nil {
+		sent, bizId, err := o.config.Verifier(o.ctx, msg)
+		if err != nil {
+			msg.LastError = fmt.Sprintf("确认上一次发送的结果失败：%s", err)
+			msg.NextAttemptAt = time.Now().Add(o.retryDelay(msg.Attempts))
+			msg.UpdatedAt = time.Now()
+			return o.store.Update(msg)
+		}
+		if sent {
+			msg.Status = StatusSent
+			msg.Unconfirmed = false
+			msg.LastError = ""
+			msg.BizId = firstNonEmpty(bizId, msg.BizId)
+			msg.UpdatedAt = time.Now()
+			return o.store.Update(msg)
+		}
+	}
+
+	// 先记录发送次数，进程在发送过程中崩溃时，重启后可以知道结果未知
+	previous := msg
+	msg.Attempts++
+	msg.Unconfirmed = true
+	msg.LastAttemptAt = time.Now()
+	msg.UpdatedAt = msg.LastAttemptAt
+	if err := o.store.Update(msg); err != nil {
+		return err
+	}
+
+	statusCode, resp, err := o.config.Sender(o.ctx, msg.Request)
+	now := time.Now()
+	if errors.Is(err, alibaba.ErrSendInProgress) {
+		return o.deferInProgress(previous, err, now)
+	}
+	msg.UpdatedAt = now
+	msg.StatusCode = statusCode
+	msg.Code = tea.StringValue(resp.Code)
+	msg.RequestId = tea.StringValue(resp.RequestId)
+	retryDelay := o.retryDelay(msg.Attempts)
+	switch {
+	case err == nil && msg.Code == "OK":
+		msg.Status = StatusSent
+		msg.Unconfirmed = false
+		msg.LastError = ""
+		msg.BizId = tea.StringValue(resp.BizId)
+		return o.store.Update(msg)
+	case err != nil:
+		msg.LastError = err.Error()
+		var rateLimitErr *alibaba.RateLimitError
+		switch {
+		case statusCode == 400:
+			// 请求本身有误，短信未发送
+			msg.Unconfirmed = false
+			msg.Status = StatusDeadLettered
+			return o.store.Update(msg)
+		case errors.As(err, &rateLimitErr):
+			msg.Unconfirmed = false
+			if rateLimitErr.RetryAfter > retryDelay {
+				retryDelay = rateLimitErr.RetryAfter
+			}
+		case errors.Is(err, alibaba.ErrCircuitOpen):
+			msg.Unconfirmed = false
+		}
+	default:
+		msg.Unconfirmed = false
+		msg.LastError = tea.StringValue(resp.Message)
+		if alibaba.IsPermanentError(msg.Code) {
+			msg.Status = StatusDeadLettered
+			return o.store.Update(msg)
+		}
+	}
+	if msg.Attempts >= o.config.MaxAttempts {
+		msg.Status = StatusDeadLettered
+	} else {
+		msg.Status = StatusFailed
+		msg.NextAttemptAt = now.Add(retryDelay)
+	}
+	return o.store.Update(msg)
+}
+
+// 幂等键仍被之前结果未知的发送（或其他进程）占用，本次没有发送：不计入发送次数，
+// 保持结果未知，配置了 Verifier 时稍后确认，否则等待幂等键过期后重新发送
+func (o *Outbox) deferInProgress(msg Message, err error, now time.Time) error {
+	msg.Unconfirmed = true
+	msg.Status = StatusFailed
+	msg.LastError = err.Error()
+	msg.UpdatedAt = now
+	if o.config.Verifier != nil {
+		msg.NextAttemptAt = now.Add(o.retryDelay(msg.Attempts))
+	} else {
+		msg.NextAttemptAt = now.Add(o.config.DedupTTL)
+	}
+	return o.store.Update(msg)
+}
+
+// 第 attempts 次发送失败后的等待时间
+func (o *Outbox) retryDelay(attempts int) time.Duration {
+	delay := o.config.RetryDelay
+	for i := 1; i < attempts && delay < o.config.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > o.config.MaxRetryDelay {
+		delay = o.config.MaxRetryDelay
+	}
+	return delay
+}
+
+func newMessageId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
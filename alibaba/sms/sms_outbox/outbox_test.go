@@ -0,0 +1,293 @@
+package sms_outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"third_party_tool_library"
+	"third_party_tool_library/alibaba"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+func request(phones ...string) alibaba.SendRequest {
+	return alibaba.SendRequest{Recipients: alibaba.Recipients(phones...), TemplateCode: "SMS_1"}
+}
+
+// 依次返回 responses 中的结果，之后一直返回最后一个
+type scriptedSender struct {
+	mu        sync.Mutex
+	calls     int
+	requests  []alibaba.SendRequest
+	responses []func() (int32, third_party_tool_library.ResponseResult, error)
+}
+
+func (s *scriptedSender) send(_ context.Context, req alibaba.SendRequest) (int32, third_party_tool_library.ResponseResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	response := s.responses[len(s.responses)-1]
+	if s.calls < len(s.responses) {
+		response = s.responses[s.calls]
+	}
+	s.calls++
+	return response()
+}
+
+func (s *scriptedSender) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func ok() (int32, third_party_tool_library.ResponseResult, error) {
+	return 200, third_party_tool_library.ResponseResult{Code: tea.String("OK"), BizId: tea.String("biz-1")}, nil
+}
+
+func code(code string) func() (int32, third_party_tool_library.ResponseResult, error) {
+	return func() (int32, third_party_tool_library.ResponseResult, error) {
+		return 200, third_party_tool_library.ResponseResult{Code: tea.String(code), Message: tea.String(code)}, nil
+	}
+}
+
+func fail(statusCode int32, err error) func() (int32, third_party_tool_library.ResponseResult, error) {
+	return func() (int32, third_party_tool_library.ResponseResult, error) {
+		return statusCode, third_party_tool_library.ResponseResult{}, err
+	}
+}
+
+func newTestOutbox(t *testing.T, config Config) (*Outbox, *MemoryStore) {
+	t.Helper()
+	store := NewMemoryStore()
+	config.PollInterval = time.Millisecond
+	config.RetryDelay = time.Millisecond
+	config.MaxRetryDelay = 2 * time.Millisecond
+	outbox, err := New(store, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = outbox.Close(context.Background()) })
+	return outbox, store
+}
+
+// 等待消息进入 status 状态
+func waitStatus(t *testing.T, outbox *Outbox, id string, status Status) Message {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		msg, err := outbox.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Status == status {
+			return msg
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("message %s is %s, want %s: %+v", id, msg.Status, status, msg)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOutboxRetriesUntilSent(t *testing.T) {
+	sender := &scriptedSender{responses: []func() (int32, third_party_tool_library.ResponseResult, error){
+		code("isp.SYSTEM_ERROR"),
+		fail(429, &alibaba.RateLimitError{Scope: alibaba.FlowScopeGlobal, RetryAfter: time.Millisecond}),
+		ok,
+	}}
+	outbox, _ := newTestOutbox(t, Config{Sender: sender.send})
+	id, err := outbox.Submit(request("13800000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := waitStatus(t, outbox, id, StatusSent)
+	if msg.Attempts != 3 || msg.Unconfirmed || msg.BizId != "biz-1" || msg.LastError != "" || msg.LastAttemptAt.IsZero() {
+		t.Fatalf("message = %+v", msg)
+	}
+	// 重新发送时使用相同的幂等键
+	for _, req := range sender.requests {
+		if req.IdempotencyKey != id {
+			t.Fatalf("IdempotencyKey = %s, want %s", req.IdempotencyKey, id)
+		}
+	}
+}
+
+func TestOutboxDeadLetters(t *testing.T) {
+	tests := []struct {
+		name     string
+		response func() (int32, third_party_tool_library.ResponseResult, error)
+		attempts int
+	}{
+		{"permanent code", code("isv.MOBILE_NUMBER_ILLEGAL"), 1},
+		{"bad request", fail(400, errors.New("模板 Code 不能为空")), 1},
+		{"max attempts", code("isp.SYSTEM_ERROR"), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &scriptedSender{responses: []func() (int32, third_party_tool_library.ResponseResult, error){tt.response}}
+			outbox, _ := newTestOutbox(t, Config{Sender: sender.send, MaxAttempts: 3})
+			id, err := outbox.Submit(request("13800000000"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := waitStatus(t, outbox, id, StatusDeadLettered)
+			if msg.Attempts != tt.attempts || msg.LastError == "" || msg.Unconfirmed {
+				t.Fatalf("message = %+v", msg)
+			}
+			// 死信不再发送
+			time.Sleep(10 * time.Millisecond)
+			if sender.count() != tt.attempts {
+				t.Fatalf("sender called %d times, want %d", sender.count(), tt.attempts)
+			}
+		})
+	}
+}
+
+func TestOutboxVerifiesUnknownOutcome(t *testing.T) {
+	sender := &scriptedSender{responses: []func() (int32, third_party_tool_library.ResponseResult, error){
+		fail(500, errors.New("read tcp: i/o timeout")),
+	}}
+	var verified atomic.Int64
+	verifier := func(_ context.Context, msg Message) (bool, string, error) {
+		verified.Add(1)
+		if !msg.Unconfirmed || msg.LastAttemptAt.IsZero() {
+			t.Errorf("verified message = %+v", msg)
+		}
+		return true, "biz-verified", nil
+	}
+	outbox, _ := newTestOutbox(t, Config{Sender: sender.send, Verifier: verifier})
+	id, err := outbox.Submit(request("13800000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := waitStatus(t, outbox, id, StatusSent)
+	if msg.Attempts != 1 || msg.BizId != "biz-verified" || sender.count() != 1 || verified.Load() != 1 {
+		t.Fatalf("message = %+v, sends %d, verifications %d", msg, sender.count(), verified.Load())
+	}
+}
+
+func TestOutboxSendInProgressIsNotAnAttempt(t *testing.T) {
+	sender := &scriptedSender{responses: []func() (int32, third_party_tool_library.ResponseResult, error){
+		fail(500, errors.New("read tcp: i/o timeout")),
+		fail(409, alibaba.ErrSendInProgress),
+		fail(409, alibaba.ErrSendInProgress),
+		ok,
+	}}
+	var verified atomic.Int64
+	verifier := func(context.Context, Message) (bool, string, error) {
+		verified.Add(1)
+		return false, "", nil
+	}
+	outbox, _ := newTestOutbox(t, Config{Sender: sender.send, Verifier: verifier, MaxAttempts: 2})
+	id, err := outbox.Submit(request("13800000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := waitStatus(t, outbox, id, StatusSent)
+	// 幂等键被占用的两次不计入发送次数，否则超过 MaxAttempts 进入死信
+	if msg.Attempts != 2 || sender.count() != 4 || verified.Load() != 3 {
+		t.Fatalf("message = %+v, sends %d, verifications %d", msg, sender.count(), verified.Load())
+	}
+}
+
+func TestOutboxSendInProgressWaitsForDedupTTL(t *testing.T) {
+	sender := &scriptedSender{responses: []func() (int32, third_party_tool_library.ResponseResult, error){
+		fail(409, alibaba.ErrSendInProgress),
+	}}
+	outbox, store := newTestOutbox(t, Config{Sender: sender.send, DedupTTL: time.Hour})
+	start := time.Now()
+	id, err := outbox.Submit(request("13800000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := waitStatus(t, outbox, id, StatusFailed)
+	if msg.Attempts != 0 || !msg.Unconfirmed || msg.NextAttemptAt.Before(start.Add(time.Hour)) {
+		t.Fatalf("message = %+v", msg)
+	}
+	if due, _ := store.Due(time.Now(), 10); len(due) != 0 {
+		t.Fatalf("message is due again before the key expires: %+v", due)
+	}
+}
+
+func TestOutboxSubmit(t *testing.T) {
+	sender := &scriptedSender{responses: []func() (int32, third_party_tool_library.ResponseResult, error){ok}}
+	outbox, _ := newTestOutbox(t, Config{Sender: sender.send})
+
+	req := request("13800000000")
+	req.OutId = "order-1"
+	id, err := outbox.Submit(req)
+	if err != nil || id != "order-1" {
+		t.Fatalf("Submit() = %s, %v, want order-1", id, err)
+	}
+	// 相同 ID 不重复写入
+	if id, err = outbox.Submit(req); err != nil || id != "order-1" {
+		t.Fatalf("second Submit() = %s, %v", id, err)
+	}
+	req.IdempotencyKey = "order-2"
+	if _, err = outbox.Submit(req); err == nil {
+		t.Fatal("Submit() with mismatched OutId succeeded")
+	}
+	waitStatus(t, outbox, "order-1", StatusSent)
+	if err = outbox.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = outbox.Submit(request("13800000001")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Submit() after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestDetailsVerifierChecksEveryRecipient(t *testing.T) {
+	attempt := time.Date(2024, 3, 1, 23, 59, 0, 0, time.FixedZone("CST", 8*3600))
+	var queried []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		phone := r.Form.Get("PhoneNumber")
+		mu.Lock()
+		queried = append(queried, phone+"@"+r.Form.Get("SendDate"))
+		mu.Unlock()
+		var records []map[string]string
+		if phone == "13800000002" {
+			records = append(records, map[string]string{"PhoneNum": phone, "OutId": "msg-1", "SendDate": "2024-03-01 23:59:01"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"Code": "OK", "Message": "OK", "TotalCount": len(records),
+			"SmsSendDetailDTOs": map[string]interface{}{"SmsSendDetailDTO": records},
+		})
+	}))
+	defer server.Close()
+	client, err := alibaba.NewClient("id", "secret", alibaba.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify := DetailsVerifier(client)
+
+	msg := Message{
+		ID:            "msg-1",
+		Request:       request("12345", "13800000001", "13800000002"),
+		Unconfirmed:   true,
+		LastAttemptAt: attempt,
+		// 确认失败时会更新 UpdatedAt，不能用于确定发送日期
+		UpdatedAt: attempt.Add(24 * time.Hour),
+	}
+	sent, _, err := verify(context.Background(), msg)
+	if err != nil || !sent {
+		t.Fatalf("verify() = %v, %v, want sent", sent, err)
+	}
+	want := []string{"13800000001@20240301", "13800000002@20240301"}
+	if len(queried) != len(want) || queried[0] != want[0] || queried[1] != want[1] {
+		t.Fatalf("queried %v, want %v", queried, want)
+	}
+
+	msg.ID = "msg-2"
+	if sent, _, err = verify(context.Background(), msg); err != nil || sent {
+		t.Fatalf("verify(msg-2) = %v, %v, want not sent", sent, err)
+	}
+}
@@ -0,0 +1,108 @@
+package sms_outbox
+
+import (
+	"errors"
+	"time"
+
+	"third_party_tool_library/alibaba"
+	"third_party_tool_library/alibaba/sms/internal/sms_store"
+)
+
+var (
+	// ErrNotFound 消息不存在
+	ErrNotFound = errors.New("发件箱中不存在该消息")
+	// ErrExists 相同 ID 的消息已存在
+	ErrExists = errors.New("发件箱中已存在相同 ID 的消息")
+)
+
+// Status 消息状态
+type Status string
+
+const (
+	// StatusPending 等待发送
+	StatusPending Status = "pending"
+	// StatusSent 已发送，接口返回 OK
+	StatusSent Status = "sent"
+	// StatusFailed 发送失败，等待重试
+	StatusFailed Status = "failed"
+	// StatusDeadLettered 不再重试：请求本身有误或超过最多发送次数，需要人工处理
+	StatusDeadLettered Status = "dead_lettered"
+)
+
+// 等待发送的状态
+func (s Status) due() bool {
+	return s == StatusPending || s == StatusFailed
+}
+
+// Message 发件箱中的消息
+type Message struct {
+	// 消息 ID，同时作为幂等键和 OutId 发送
+	ID string `json:"id"`
+	// 发送请求
+	Request alibaba.SendRequest `json:"request"`
+	// 状态
+	Status Status `json:"status"`
+	// 已开始的发送次数
+	Attempts int `json:"attempts"`
+	// 最近一次发送已开始但结果未知（进程崩溃或调用出错），短信可能已经发出
+	Unconfirmed bool `json:"unconfirmed"`
+	// 最近一次发送的接口响应编码
+	StatusCode int32 `json:"status_code"`
+	// 最近一次发送的业务编码
+	Code string `json:"code"`
+	// 最近一次发送失败的原因
+	LastError string `json:"last_error"`
+	// 发送成功时的发送回执 ID
+	BizId string `json:"biz_id"`
+	// 最近一次发送的请求 ID
+	RequestId string `json:"request_id"`
+	// 写入时间
+	CreatedAt time.Time `json:"created_at"`
+	// 更新时间
+	UpdatedAt time.Time `json:"updated_at"`
+	// 最近一次开始发送的时间，DetailsVerifier 按该时间的日期查询发送记录，未发送过时为零值
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+	// 下次发送的时间
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// Store 发件箱存储
+/**
+ * 默认提供基于 bbolt 文件的 BoltStore 和仅用于测试的 MemoryStore，
+ * 需要多个实例共享时可以基于数据库实现该接口
+ */
+type Store interface {
+	// Create 写入新消息，相同 ID 的消息已存在时返回 ErrExists
+	Create(msg Message) error
+	// Update 更新消息，消息不存在时返回 ErrNotFound
+	Update(msg Message) error
+	// Get 按 ID 查询消息，消息不存在时返回 ErrNotFound
+	Get(id string) (Message, error)
+	// Due 查询等待发送（pending、failed）且到了发送时间的消息，按发送时间升序，最多 limit 条
+	Due(now time.Time, limit int) ([]Message, error)
+	// Close 关闭存储
+	Close() error
+}
+
+// 消息的存储方式：等待发送（pending、failed）的消息按下次发送时间索引
+var messageSchema = sms_store.Schema[Message]{
+	Name:  "发件箱消息",
+	Items: []byte("messages"),
+	Index: []byte("due"),
+	ID:    func(msg Message) string { return msg.ID },
+	Due: func(msg Message) (time.Time, bool) {
+		return msg.NextAttemptAt, msg.Status.due()
+	},
+	ErrExists:   ErrExists,
+	ErrNotFound: ErrNotFound,
+}
+
+// MemoryStore 内存中的发件箱存储，进程退出后消息丢失，仅用于测试
+type MemoryStore struct {
+	*sms_store.MemoryStore[Message]
+}
+
+// NewMemoryStore 创建内存中的发件箱存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{MemoryStore: sms_store.NewMemoryStore(messageSchema)}
+}
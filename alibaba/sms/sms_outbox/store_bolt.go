@@ -0,0 +1,29 @@
+package sms_outbox
+
+import (
+	"fmt"
+
+	"third_party_tool_library/alibaba/sms/internal/sms_store"
+)
+
+// BoltStore 基于 bbolt 文件的发件箱存储，同一个文件只能被一个进程打开
+/**
+ * 文件中 messages 保存消息，键为消息 ID；due 为等待发送的消息索引，键为 8 字节的下次发送时间（UnixNano，大端序）加消息 ID
+ */
+type BoltStore struct {
+	*sms_store.BoltStore[Message]
+}
+
+// OpenBoltStore
+/** 打开发件箱文件，不存在时创建
+ * @param path 文件路径
+ * @return *BoltStore 发件箱存储，使用完毕后调用 Close
+ * @return error 文件已被其他进程打开时等待 1 秒后返回超时错误
+ */
+func OpenBoltStore(path string) (*BoltStore, error) {
+	store, err := sms_store.OpenBoltStore(path, messageSchema)
+	if err != nil {
+		return nil, fmt.Errorf("打开发件箱文件 %s 失败：%w", path, err)
+	}
+	return &BoltStore{BoltStore: store}, nil
+}
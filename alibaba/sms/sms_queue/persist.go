@@ -0,0 +1,97 @@
+package sms_queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"third_party_tool_library/alibaba"
+)
+
+// Persister 保存队列关闭时未发送的短信
+type Persister interface {
+	// Save 保存未发送的短信，之后可以读取并重新入队
+	Save(requests []alibaba.SendRequest) error
+}
+
+// FilePersister 将未发送的短信以 JSON Lines 格式追加保存到文件中
+/**
+ * 进程重启后使用 Load 读取并重新入队，例如：
+ *	persister := sms_queue.NewFilePersister("/var/lib/app/sms_pending.jsonl")
+ *	requests, _ := persister.Load()
+ *	for _, req := range requests {
+ *		queue.Enqueue(ctx, req)
+ *	}
+ */
+type FilePersister struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFilePersister
+/** 创建文件存储
+ * @param path 文件路径，不存在时在保存时创建
+ */
+func NewFilePersister(path string) *FilePersister {
+	return &FilePersister{path: path}
+}
+
+func (p *FilePersister) Save(requests []alibaba.SendRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	file, err := os.OpenFile(p.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, req := range requests {
+		if err := encoder.Encode(req); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load
+/** 读取保存的短信并清空文件，读取后请重新入队
+ * @return []alibaba.SendRequest 保存的短信，文件不存在时为空
+ * @return error 读取或解析失败时返回错误，此时不清空文件
+ */
+func (p *FilePersister) Load() ([]alibaba.SendRequest, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	file, err := os.Open(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var requests []alibaba.SendRequest
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var req alibaba.SendRequest
+		if err := decoder.Decode(&req); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("解析文件 %s 中第 %d 条短信失败：%w", p.path, len(requests)+1, err)
+		}
+		requests = append(requests, req)
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return requests, os.Truncate(p.path, 0)
+}
@@ -0,0 +1,353 @@
+package sms_queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"third_party_tool_library"
+	"third_party_tool_library/alibaba"
+	"third_party_tool_library/alibaba/sms/sms_execute"
+)
+
+const (
+	// DefaultWorkers 默认的发送协程数
+	DefaultWorkers = 4
+	// DefaultQueueSize 默认的队列容量
+	DefaultQueueSize = 1000
+)
+
+var (
+	// ErrQueueFull 队列已满
+	ErrQueueFull = errors.New("短信发送队列已满")
+	// ErrQueueClosed 队列已关闭，不再接收新的短信
+	ErrQueueClosed = errors.New("短信发送队列已关闭")
+	// ErrNotSent 队列关闭时短信还未发送，见 Result.Persisted
+	ErrNotSent = errors.New("短信发送队列关闭时短信未发送")
+)
+
+// Sender 发送一条短信，例如 ExecuteSender、ClientSender 的返回值
+type Sender func(ctx context.Context, req alibaba.SendRequest) (int32, third_party_tool_library.ResponseResult, error)
+
+// ExecuteSender
+/** 使用 sms_execute.SendContext 发送短信，幂等记录、限流和熔断见 sms_execute 的对应配置
+ * @param accessKeyId 访问秘钥ID
+ * @param accessKeySecret 访问秘钥凭证
+ */
+func ExecuteSender(accessKeyId, accessKeySecret string) Sender {
+	return func(ctx context.Context, req alibaba.SendRequest) (int32, third_party_tool_library.ResponseResult, error) {
+		return sms_execute.SendContext(ctx, accessKeyId, accessKeySecret, req)
+	}
+}
+
+// ClientSender
+/** 使用已创建的客户端发送短信，发送频繁时比 ExecuteSender 更高效
+ * @param client 短信客户端
+ * @param opts 每次发送的调用配置项，例如 alibaba.CallReadTimeout
+ */
+func ClientSender(client *alibaba.Client, opts ...alibaba.CallOption) Sender {
+	return func(ctx context.Context, req alibaba.SendRequest) (int32, third_party_tool_library.ResponseResult, error) {
+		return client.SendContext(ctx, req, opts...)
+	}
+}
+
+// Config 发送队列配置
+type Config struct {
+	// 发送协程数，为 0 时使用 DefaultWorkers
+	Workers int
+	// 队列容量，为 0 时使用 DefaultQueueSize
+	QueueSize int
+	// 每条短信的发送超时，为 0 时不限制（仍受客户端请求超时的限制）
+	SendTimeout time.Duration
+	// 关闭队列时保存未发送的短信，为 nil 时未发送的短信直接丢弃，见 FilePersister
+	Persister Persister
+}
+
+// Result 一条短信的发送结果
+type Result struct {
+	// 接口响应编码
+	StatusCode int32
+	// 响应对象
+	Response third_party_tool_library.ResponseResult
+	// 错误响应对象，队列关闭时未发送的短信为 ErrNotSent
+	Err error
+	// 队列关闭时短信未发送，已由 Config.Persister 保存
+	Persisted bool
+}
+
+// Future 入队短信的发送结果
+type Future struct {
+	req  alibaba.SendRequest
+	done chan struct{}
+
+	mu        sync.Mutex
+	result    Result
+	callbacks []func(Result)
+}
+
+func newFuture(req alibaba.SendRequest) *Future {
+	return &Future{req: req, done: make(chan struct{})}
+}
+
+// Request 入队的发送请求
+func (f *Future) Request() alibaba.SendRequest {
+	return f.req
+}
+
+// Done 发送完成（包括失败和队列关闭时未发送）时关闭的通道
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait
+/** 等待发送完成
+ * @param ctx 等待的截止时间，ctx 被取消时不影响短信发送
+ * @return Result 发送结果
+ * @return error ctx 被取消时返回 ctx.Err()
+ */
+func (f *Future) Wait(ctx context.Context) (Result, error) {
+	select {
+	case <-f.done:
+		return f.result, nil
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	}
+}
+
+// OnDone 注册发送完成后的回调，在发送协程中调用；已经完成时立即在当前协程中调用
+func (f *Future) OnDone(callback func(Result)) {
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		callback(f.result)
+		return
+	default:
+	}
+	f.callbacks = append(f.callbacks, callback)
+	f.mu.Unlock()
+}
+
+func (f *Future) complete(result Result) {
+	f.mu.Lock()
+	f.result = result
+	close(f.done)
+	callbacks := f.callbacks
+	f.callbacks = nil
+	f.mu.Unlock()
+	for _, callback := range callbacks {
+		callback(result)
+	}
+}
+
+// Queue 异步短信发送队列
+/**
+ * 调用方入队后立即返回 Future，由固定数量的发送协程从有界队列中取出短信发送，例如：
+ *	queue, _ := sms_queue.New(sms_queue.ExecuteSender(accessKeyId, accessKeySecret), sms_queue.Config{})
+ *	future, err := queue.TryEnqueue(req)
+ *	...
+ *	queue.Shutdown(ctx)
+ */
+type Queue struct {
+	sender Sender
+	config Config
+	items  chan *Future
+
+	// 保护 closed，入队时持有读锁，保证关闭 items 之后没有协程再写入
+	mu      sync.RWMutex
+	closed  bool
+	closing chan struct{}
+	// 关闭超时后通知发送协程不再发送
+	abort chan struct{}
+	// 发送使用的 ctx，关闭超时后取消
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	leftoverMu sync.Mutex
+	leftover   []*Future
+
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// New
+/** 创建发送队列并启动发送协程
+ * @param sender 发送函数，例如 ExecuteSender(accessKeyId, accessKeySecret)
+ * @param config 队列配置，零值使用默认配置
+ * @return *Queue 发送队列
+ * @return error 配置不合法时返回错误
+ */
+func New(sender Sender, config Config) (*Queue, error) {
+	if sender == nil {
+		return nil, errors.New("发送函数不能为空")
+	}
+	if config.Workers < 0 || config.QueueSize < 0 || config.SendTimeout < 0 {
+		return nil, errors.New("发送协程数、队列容量和发送超时不能小于 0")
+	}
+	if config.Workers == 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		sender:  sender,
+		config:  config,
+		items:   make(chan *Future, config.QueueSize),
+		closing: make(chan struct{}),
+		abort:   make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	q.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go q.work()
+	}
+	return q, nil
+}
+
+// Enqueue
+/** 短信入队，队列已满时等待，直到有空位、ctx 被取消或队列关闭
+ * @param ctx 等待入队的截止时间，不影响之后的发送
+ * @param req 发送请求
+ * @return *Future 发送结果
+ * @return error 队列关闭时返回 ErrQueueClosed，ctx 被取消时返回 ctx.Err()
+ */
+func (q *Queue) Enqueue(ctx context.Context, req alibaba.SendRequest) (*Future, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+	future := newFuture(req)
+	select {
+	case q.items <- future:
+		return future, nil
+	case <-q.closing:
+		return nil, ErrQueueClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// TryEnqueue
+/** 短信入队，队列已满时不等待，立即返回 ErrQueueFull，适合在 HTTP 请求中使用，由调用方决定降级或返回 503
+ * @param req 发送请求
+ * @return *Future 发送结果
+ * @return error 队列已满时返回 ErrQueueFull，队列关闭时返回 ErrQueueClosed
+ */
+func (q *Queue) TryEnqueue(req alibaba.SendRequest) (*Future, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+	future := newFuture(req)
+	select {
+	case q.items <- future:
+		return future, nil
+	default:
+		return nil, ErrQueueFull
+	}
+}
+
+// Len 队列中等待发送的短信数
+func (q *Queue) Len() int {
+	return len(q.items)
+}
+
+// Cap 队列容量
+func (q *Queue) Cap() int {
+	return cap(q.items)
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for future := range q.items {
+		select {
+		case <-q.abort:
+			q.leftoverMu.Lock()
+			q.leftover = append(q.leftover, future)
+			q.leftoverMu.Unlock()
+			continue
+		default:
+		}
+		future.complete(q.send(future.req))
+	}
+}
+
+func (q *Queue) send(req alibaba.SendRequest) Result {
+	ctx := q.ctx
+	if q.config.SendTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, q.config.SendTimeout)
+		defer cancel()
+	}
+	statusCode, resp, err := q.sender(ctx, req)
+	return Result{StatusCode: statusCode, Response: resp, Err: err}
+}
+
+// Shutdown
+/** 关闭队列：不再接收新的短信，等待队列中的短信发送完成
+ * ctx 被取消或超过截止时间时不再发送剩余的短信，并取消正在发送的短信的 ctx：发送函数不再等待调用结果，
+ * 短信可能已经发出（见 alibaba.ErrAbandoned），这些短信不会被保存；
+ * 未发送的短信由 Config.Persister 保存，其 Future 的结果为 ErrNotSent
+ * 重复调用返回第一次调用的结果
+ * @param ctx 等待发送完成的截止时间
+ * @return error 全部发送完成时为 nil，否则为包装后的 ctx.Err() 或保存失败的错误
+ */
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.shutdownOnce.Do(func() {
+		q.shutdownErr = q.shutdown(ctx)
+	})
+	return q.shutdownErr
+}
+
+func (q *Queue) shutdown(ctx context.Context) error {
+	close(q.closing)
+	q.mu.Lock()
+	q.closed = true
+	close(q.items)
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+	close(q.abort)
+	q.cancel()
+	<-done
+
+	leftover := q.leftover
+	result := Result{Err: ErrNotSent}
+	var persistErr error
+	if q.config.Persister != nil && len(leftover) > 0 {
+		requests := make([]alibaba.SendRequest, len(leftover))
+		for i, future := range leftover {
+			requests[i] = future.req
+		}
+		persistErr = q.config.Persister.Save(requests)
+		result.Persisted = persistErr == nil
+	}
+	for _, future := range leftover {
+		future.complete(result)
+	}
+	if persistErr != nil {
+		return fmt.Errorf("保存 %d 条未发送的短信失败：%w", len(leftover), persistErr)
+	}
+	if len(leftover) == 0 {
+		return fmt.Errorf("短信发送队列关闭超时，已停止等待正在发送的短信：%w", ctx.Err())
+	}
+	return fmt.Errorf("短信发送队列关闭时 %d 条短信未发送：%w", len(leftover), ctx.Err())
+}
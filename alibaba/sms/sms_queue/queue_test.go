@@ -0,0 +1,177 @@
+package sms_queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"third_party_tool_library"
+	"third_party_tool_library/alibaba"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+func request(phone string) alibaba.SendRequest {
+	return alibaba.SendRequest{Recipients: alibaba.Recipients(phone), TemplateCode: "SMS_1"}
+}
+
+func okSender(_ context.Context, req alibaba.SendRequest) (int32, third_party_tool_library.ResponseResult, error) {
+	return 200, third_party_tool_library.ResponseResult{Code: tea.String("OK"), BizId: tea.String(req.Recipients[0].PhoneNumber)}, nil
+}
+
+func TestShutdownDrainsQueue(t *testing.T) {
+	queue, err := New(okSender, Config{Workers: 2, QueueSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var futures []*Future
+	for _, phone := range []string{"13800000001", "13800000002", "13800000003"} {
+		future, err := queue.TryEnqueue(request(phone))
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, future)
+	}
+	if err = queue.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, future := range futures {
+		result, err := future.Wait(context.Background())
+		if err != nil || result.Err != nil || tea.StringValue(result.Response.BizId) != future.Request().Recipients[0].PhoneNumber {
+			t.Fatalf("result = %+v, %v", result, err)
+		}
+	}
+	if _, err = queue.TryEnqueue(request("13800000004")); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("TryEnqueue() after Shutdown = %v, want ErrQueueClosed", err)
+	}
+	if _, err = queue.Enqueue(context.Background(), request("13800000004")); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("Enqueue() after Shutdown = %v, want ErrQueueClosed", err)
+	}
+}
+
+// 第一条短信阻塞到 ctx 结束，模拟关闭时正在发送的短信
+func blockingSender(started chan<- struct{}) Sender {
+	var once sync.Once
+	return func(ctx context.Context, req alibaba.SendRequest) (int32, third_party_tool_library.ResponseResult, error) {
+		once.Do(func() { close(started) })
+		<-ctx.Done()
+		return 500, third_party_tool_library.ResponseResult{}, ctx.Err()
+	}
+}
+
+func TestShutdownPersistsUnsentRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pending.jsonl")
+	persister := NewFilePersister(path)
+	started := make(chan struct{})
+	queue, err := New(blockingSender(started), Config{Workers: 1, QueueSize: 10, Persister: persister})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inFlight, _ := queue.TryEnqueue(request("13800000001"))
+	<-started
+	var pending []*Future
+	for _, phone := range []string{"13800000002", "13800000003"} {
+		future, err := queue.TryEnqueue(request(phone))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pending = append(pending, future)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = queue.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() = %v, want context.DeadlineExceeded", err)
+	}
+	// 重复调用返回相同的结果
+	if err2 := queue.Shutdown(context.Background()); err2 != err {
+		t.Fatalf("second Shutdown() = %v, want %v", err2, err)
+	}
+
+	// 正在发送的短信不保存，结果为发送函数返回的错误
+	result, _ := inFlight.Wait(context.Background())
+	if result.Persisted || !errors.Is(result.Err, context.Canceled) {
+		t.Fatalf("in-flight result = %+v", result)
+	}
+	for _, future := range pending {
+		result, _ := future.Wait(context.Background())
+		if !result.Persisted || !errors.Is(result.Err, ErrNotSent) {
+			t.Fatalf("pending result = %+v", result)
+		}
+	}
+
+	requests, err := persister.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[0].Recipients[0].PhoneNumber != "13800000002" || requests[1].Recipients[0].PhoneNumber != "13800000003" {
+		t.Fatalf("Load() = %+v", requests)
+	}
+	// 读取后清空
+	if requests, err = persister.Load(); err != nil || len(requests) != 0 {
+		t.Fatalf("second Load() = %+v, %v", requests, err)
+	}
+}
+
+type failingPersister struct{}
+
+func (failingPersister) Save([]alibaba.SendRequest) error {
+	return errors.New("磁盘已满")
+}
+
+func TestShutdownPersistFailure(t *testing.T) {
+	started := make(chan struct{})
+	queue, err := New(blockingSender(started), Config{Workers: 1, QueueSize: 10, Persister: failingPersister{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = queue.TryEnqueue(request("13800000001"))
+	<-started
+	future, _ := queue.TryEnqueue(request("13800000002"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = queue.Shutdown(ctx); err == nil {
+		t.Fatal("Shutdown() with failing persister succeeded")
+	}
+	result, _ := future.Wait(context.Background())
+	if result.Persisted || !errors.Is(result.Err, ErrNotSent) {
+		t.Fatalf("pending result = %+v", result)
+	}
+}
+
+func TestTryEnqueueFull(t *testing.T) {
+	started := make(chan struct{})
+	queue, err := New(blockingSender(started), Config{Workers: 1, QueueSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_ = queue.Shutdown(ctx)
+	}()
+	_, _ = queue.TryEnqueue(request("13800000001"))
+	<-started
+	if _, err = queue.TryEnqueue(request("13800000002")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = queue.TryEnqueue(request("13800000003")); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("TryEnqueue() = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestFilePersisterLoadMissingFile(t *testing.T) {
+	persister := NewFilePersister(filepath.Join(t.TempDir(), "missing.jsonl"))
+	requests, err := persister.Load()
+	if err != nil || requests != nil {
+		t.Fatalf("Load() = %+v, %v", requests, err)
+	}
+	if _, err = os.Stat(persister.path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Load() created the file: %v", err)
+	}
+}
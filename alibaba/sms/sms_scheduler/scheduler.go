@@ -0,0 +1,382 @@
+package sms_scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"third_party_tool_library/alibaba"
+)
+
+const (
+	// DefaultPollInterval 默认的检查间隔，决定了发送时间的精度
+	DefaultPollInterval = time.Second
+	// DefaultBatchSize 每次从存储中取出的最多任务数
+	DefaultBatchSize = 100
+)
+
+var (
+	// ErrNotScheduled 任务已发送、已取消或已错过，不能再取消或改期
+	ErrNotScheduled = errors.New("定时任务不在等待发送状态")
+	// ErrClosed 定时器已关闭
+	ErrClosed = errors.New("短信定时器已关闭")
+)
+
+// Dispatcher 到时间后接收短信的发件箱，例如 *sms_outbox.Outbox
+/**
+ * Submit 需要持久化并按消息 ID（req.IdempotencyKey）去重：任务交给发件箱后、记录状态前进程崩溃时，重启后会再次提交同一任务
+ */
+type Dispatcher interface {
+	Submit(req alibaba.SendRequest) (string, error)
+}
+
+// Config 定时器配置
+type Config struct {
+	// 检查间隔，为 0 时使用 DefaultPollInterval
+	PollInterval time.Duration
+	// 每次从存储中取出的最多任务数，为 0 时使用 DefaultBatchSize
+	BatchSize int
+	// 允许的最大延迟：进程停止期间到期的任务，重启后超过发送时间该时长的不再发送，标记为 JobMissed；为 0 时总是补发
+	MaxLateness time.Duration
+	// 后台读写存储或提交发件箱出错的回调，可用于记录日志，可为空
+	OnError func(err error)
+}
+
+// Scheduler 短信定时器
+/**
+ * 定时任务写入存储，到时间后交给发件箱发送，进程重启后继续等待未到期的任务，例如 24 小时前的预约提醒：
+ *	loc, _ := time.LoadLocation("Asia/Shanghai")
+ *	appointment := time.Date(2024, 6, 1, 15, 30, 0, 0, loc)
+ *	id, err := scheduler.Schedule(req, appointment.Add(-24*time.Hour))
+ * 发送时间必须使用明确的时区（time.LoadLocation 加载的 IANA 时区或 UTC），
+ * 按当地时间指定日期和时间时使用 LocalTime，避免服务器时区或夏令时切换导致发送时间偏移
+ */
+type Scheduler struct {
+	store      Store
+	dispatcher Dispatcher
+	config     Config
+
+	// 保证状态变化的顺序：到期发送与取消、改期不会同时进行
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// New
+/** 创建定时器并启动后台检查协程
+ * @param store 定时任务存储，例如 OpenBoltStore 的返回值，由调用方关闭
+ * @param dispatcher 到时间后接收短信的发件箱，例如 sms_outbox.New 的返回值
+ * @param config 定时器配置，零值使用默认配置
+ * @return *Scheduler 定时器
+ * @return error 配置不合法时返回错误
+ */
+func New(store Store, dispatcher Dispatcher, config Config) (*Scheduler, error) {
+	if store == nil {
+		return nil, errors.New("定时任务存储不能为空")
+	}
+	if dispatcher == nil {
+		return nil, errors.New("发件箱不能为空")
+	}
+	if config.PollInterval < 0 || config.BatchSize < 0 || config.MaxLateness < 0 {
+		return nil, errors.New("定时器配置不能小于 0")
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	s := &Scheduler{
+		store:      store,
+		dispatcher: dispatcher,
+		config:     config,
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// LocalTime
+/** 按指定时区的当地日期和时间生成发送时间，例如上海时间 10:00 发布活动：
+ *	loc, _ := time.LoadLocation("Asia/Shanghai")
+ *	at, err := sms_scheduler.LocalTime(2024, time.June, 1, 10, 0, loc)
+ * @param loc 时区，不能为空或 time.Local
+ * @return time.Time 发送时间
+ * @return error 时区不明确，或该当地时间因夏令时切换不存在时返回错误
+ */
+func LocalTime(year int, month time.Month, day, hour, min int, loc *time.Location) (time.Time, error) {
+	if err := checkLocation(loc); err != nil {
+		return time.Time{}, err
+	}
+	at := time.Date(year, month, day, hour, min, 0, 0, loc)
+	if at.Year() != year || at.Month() != month || at.Day() != day || at.Hour() != hour || at.Minute() != min {
+		return time.Time{}, fmt.Errorf("%04d-%02d-%02d %02d:%02d 在时区 %s 中不存在", year, month, day, hour, min, loc)
+	}
+	return at, nil
+}
+
+func checkLocation(loc *time.Location) error {
+	if loc == nil || loc == time.Local {
+		return errors.New("请使用明确的时区，例如 time.LoadLocation(\"Asia/Shanghai\") 或 time.UTC")
+	}
+	return nil
+}
+
+// 校验发送时间，返回其时区名称
+func timeZoneOf(at time.Time) (string, error) {
+	if at.IsZero() {
+		return "", errors.New("发送时间不能为空")
+	}
+	loc := at.Location()
+	if err := checkLocation(loc); err != nil {
+		return "", err
+	}
+	// 时区需要能在重启后按名称加载，time.FixedZone 等自定义时区无法保存
+	loaded, err := time.LoadLocation(loc.String())
+	if err != nil {
+		return "", fmt.Errorf("时区 %s 无法按名称加载，请使用 time.LoadLocation 加载 IANA 时区：%w", loc, err)
+	}
+	_, offset := at.Zone()
+	if _, loadedOffset := at.In(loaded).Zone(); loadedOffset != offset {
+		return "", fmt.Errorf("时区 %s 与按名称加载的时区不一致，请使用 time.LoadLocation 加载 IANA 时区", loc)
+	}
+	return loc.String(), nil
+}
+
+// Schedule
+/** 定时发送短信
+ * 任务 ID 依次使用 req.IdempotencyKey、req.OutId，都为空时随机生成，与发件箱的消息 ID 规则相同；
+ * 同时指定时 OutId 必须与幂等键相同，否则发件箱无法接收；相同 ID 的任务已存在时返回 ErrExists
+ * @param req 发送请求
+ * @param at 发送时间，必须使用明确的时区，已经过去的时间会立即发送
+ * @return string 任务 ID，用于 Get、Cancel、Reschedule
+ * @return error 参数不合法或写入失败时返回错误
+ */
+func (s *Scheduler) Schedule(req alibaba.SendRequest, at time.Time) (string, error) {
+	select {
+	case <-s.stop:
+		return "", ErrClosed
+	default:
+	}
+	if len(req.Recipients) == 0 {
+		return "", errors.New("接收对象不能为空")
+	}
+	timeZone, err := timeZoneOf(at)
+	if err != nil {
+		return "", err
+	}
+	id := req.IdempotencyKey
+	if id == "" {
+		id = req.OutId
+	}
+	if id == "" {
+		if id, err = newJobId(); err != nil {
+			return "", err
+		}
+	}
+	if req.OutId != "" && req.OutId != id {
+		return "", errors.New("指定幂等键时 OutId 必须为空或与幂等键相同")
+	}
+	req.IdempotencyKey = id
+	now := time.Now()
+	err = s.store.Create(Job{
+		ID:        id,
+		Request:   req,
+		At:        at,
+		TimeZone:  timeZone,
+		Status:    JobScheduled,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// ScheduleAfter
+/** 延迟发送短信
+ * @param req 发送请求
+ * @param delay 延迟时间
+ * @return string 任务 ID
+ * @return error 参数不合法或写入失败时返回错误
+ */
+func (s *Scheduler) ScheduleAfter(req alibaba.SendRequest, delay time.Duration) (string, error) {
+	return s.Schedule(req, time.Now().UTC().Add(delay))
+}
+
+// Get
+/** 按 ID 查询定时任务
+ * @param id 任务 ID
+ * @return Job 定时任务，交给发件箱之后的发送状态请按相同 ID 在发件箱中查询
+ * @return error 任务不存在时返回 ErrNotFound
+ */
+func (s *Scheduler) Get(id string) (Job, error) {
+	return s.store.Get(id)
+}
+
+// Cancel
+/** 取消等待发送的定时任务
+ * @param id 任务 ID
+ * @return error 任务不存在时返回 ErrNotFound，已发送、已取消或已错过时返回 ErrNotScheduled
+ */
+func (s *Scheduler) Cancel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.scheduledJob(id)
+	if err != nil {
+		return err
+	}
+	job.Status = JobCanceled
+	job.UpdatedAt = time.Now()
+	return s.store.Update(job)
+}
+
+// Reschedule
+/** 修改等待发送的定时任务的发送时间
+ * @param id 任务 ID
+ * @param at 新的发送时间，必须使用明确的时区
+ * @return error 任务不存在时返回 ErrNotFound，已发送、已取消或已错过时返回 ErrNotScheduled
+ */
+func (s *Scheduler) Reschedule(id string, at time.Time) error {
+	timeZone, err := timeZoneOf(at)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.scheduledJob(id)
+	if err != nil {
+		return err
+	}
+	job.At = at
+	job.TimeZone = timeZone
+	job.UpdatedAt = time.Now()
+	return s.store.Update(job)
+}
+
+// 查询等待发送的任务，调用方需持有锁
+func (s *Scheduler) scheduledJob(id string) (Job, error) {
+	job, err := s.store.Get(id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.Status != JobScheduled {
+		return Job{}, fmt.Errorf("%w：%s 的状态为 %s", ErrNotScheduled, id, job.Status)
+	}
+	return job, nil
+}
+
+// Close
+/** 停止后台检查，等待正在提交的任务完成；未到期的任务保留在存储中，下次启动后继续等待
+ * 不会关闭存储，请在之后调用 Store.Close
+ * @param ctx 等待的截止时间
+ * @return error 超过截止时间时返回 ctx.Err()
+ */
+func (s *Scheduler) Close(ctx context.Context) error {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		// 取满一批时说明还有到期的任务，继续处理
+		for s.dispatchDue() == s.config.BatchSize {
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+		}
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 将一批到期的任务交给发件箱，返回取出的任务数，有任务提交失败时返回 0，等到下次检查再重试
+func (s *Scheduler) dispatchDue() int {
+	jobs, err := s.store.Due(time.Now(), s.config.BatchSize)
+	if err != nil {
+		s.reportError(fmt.Errorf("查询到期的定时任务失败：%w", err))
+		return 0
+	}
+	failed := false
+	for _, job := range jobs {
+		select {
+		case <-s.stop:
+			return len(jobs)
+		default:
+		}
+		if err := s.dispatch(job.ID); err != nil {
+			s.reportError(fmt.Errorf("发送定时任务 %s 失败：%w", job.ID, err))
+			failed = true
+		}
+	}
+	if failed {
+		return 0
+	}
+	return len(jobs)
+}
+
+// 将到期的任务交给发件箱，任务在此期间被取消或改期时跳过
+func (s *Scheduler) dispatch(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.store.Get(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if job.Status != JobScheduled || job.At.After(now) {
+		return nil
+	}
+	job.UpdatedAt = now
+	if s.config.MaxLateness > 0 && now.Sub(job.At) > s.config.MaxLateness {
+		job.Status = JobMissed
+		return s.store.Update(job)
+	}
+	if _, err := s.dispatcher.Submit(job.Request); err != nil {
+		// 保持等待发送状态，下次检查时重试
+		job.LastError = err.Error()
+		if updateErr := s.store.Update(job); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+	job.Status = JobDispatched
+	job.LastError = ""
+	return s.store.Update(job)
+}
+
+func (s *Scheduler) reportError(err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(err)
+	}
+}
+
+func newJobId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,281 @@
+package sms_scheduler
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"third_party_tool_library"
+	"third_party_tool_library/alibaba"
+	"third_party_tool_library/alibaba/sms/sms_outbox"
+)
+
+func request(phone string) alibaba.SendRequest {
+	return alibaba.SendRequest{Recipients: alibaba.Recipients(phone), TemplateCode: "SMS_1"}
+}
+
+// 记录提交的短信
+type recordingDispatcher struct {
+	mu       sync.Mutex
+	requests []alibaba.SendRequest
+}
+
+func (d *recordingDispatcher) Submit(req alibaba.SendRequest) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, req)
+	return req.IdempotencyKey, nil
+}
+
+func (d *recordingDispatcher) submitted() []alibaba.SendRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]alibaba.SendRequest(nil), d.requests...)
+}
+
+func newTestScheduler(t *testing.T, store Store, dispatcher Dispatcher) *Scheduler {
+	t.Helper()
+	scheduler, err := New(store, dispatcher, Config{PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = scheduler.Close(context.Background()) })
+	return scheduler
+}
+
+// 等待任务进入 status 状态
+func waitStatus(t *testing.T, scheduler *Scheduler, id string, status JobStatus) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := scheduler.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", id, job.Status, status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduleDispatchesDueJobs(t *testing.T) {
+	dispatcher := &recordingDispatcher{}
+	scheduler := newTestScheduler(t, NewMemoryStore(), dispatcher)
+
+	later, err := scheduler.ScheduleAfter(request("13800000001"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now, err := scheduler.ScheduleAfter(request("13800000002"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitStatus(t, scheduler, now, JobDispatched)
+	if job, _ := scheduler.Get(later); job.Status != JobScheduled {
+		t.Fatalf("future job status = %s", job.Status)
+	}
+	submitted := dispatcher.submitted()
+	if len(submitted) != 1 || submitted[0].IdempotencyKey != now || submitted[0].Recipients[0].PhoneNumber != "13800000002" {
+		t.Fatalf("submitted %+v", submitted)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	scheduler := newTestScheduler(t, NewMemoryStore(), &recordingDispatcher{})
+	id, err := scheduler.ScheduleAfter(request("13800000001"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = scheduler.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+	if job, _ := scheduler.Get(id); job.Status != JobCanceled {
+		t.Fatalf("status = %s, want canceled", job.Status)
+	}
+	if err = scheduler.Cancel(id); !errors.Is(err, ErrNotScheduled) {
+		t.Fatalf("second Cancel() = %v, want ErrNotScheduled", err)
+	}
+	if err = scheduler.Reschedule(id, time.Now().UTC()); !errors.Is(err, ErrNotScheduled) {
+		t.Fatalf("Reschedule() after Cancel = %v, want ErrNotScheduled", err)
+	}
+	if err = scheduler.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Cancel(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestReschedule(t *testing.T) {
+	dispatcher := &recordingDispatcher{}
+	scheduler := newTestScheduler(t, NewMemoryStore(), dispatcher)
+	id, err := scheduler.ScheduleAfter(request("13800000001"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	// 改期到已经过去的时间立即发送，并记录新的时区
+	if err = scheduler.Reschedule(id, time.Now().In(tokyo).Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	job := waitStatus(t, scheduler, id, JobDispatched)
+	if job.TimeZone != "Asia/Tokyo" || len(dispatcher.submitted()) != 1 {
+		t.Fatalf("job = %+v, submitted %d", job, len(dispatcher.submitted()))
+	}
+	if err = scheduler.Reschedule(id, time.Now().UTC().Add(time.Hour)); !errors.Is(err, ErrNotScheduled) {
+		t.Fatalf("Reschedule() after dispatch = %v, want ErrNotScheduled", err)
+	}
+}
+
+func TestScheduleTimeZones(t *testing.T) {
+	scheduler := newTestScheduler(t, NewMemoryStore(), &recordingDispatcher{})
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := time.Date(2030, time.June, 1, 10, 0, 0, 0, shanghai)
+	id, err := scheduler.Schedule(request("13800000001"), at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, _ := scheduler.Get(id)
+	local, err := job.LocalAt()
+	if err != nil || job.TimeZone != "Asia/Shanghai" || !local.Equal(at) || local.Hour() != 10 {
+		t.Fatalf("LocalAt() = %v, %v, time zone %s", local, err, job.TimeZone)
+	}
+
+	invalid := []time.Time{
+		{},
+		time.Now().Add(time.Hour).In(time.Local),
+		time.Now().Add(time.Hour).In(time.FixedZone("CST", 8*3600)),
+	}
+	for _, at := range invalid {
+		if _, err := scheduler.Schedule(request("13800000001"), at); err == nil {
+			t.Errorf("Schedule(%v) succeeded", at)
+		}
+	}
+}
+
+func TestLocalTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	at, err := LocalTime(2024, time.March, 9, 2, 30, newYork)
+	if err != nil || at.Hour() != 2 || at.Minute() != 30 {
+		t.Fatalf("LocalTime() = %v, %v", at, err)
+	}
+	// 夏令时开始时 02:00-03:00 不存在
+	if _, err = LocalTime(2024, time.March, 10, 2, 30, newYork); err == nil {
+		t.Fatal("LocalTime() in the DST gap succeeded")
+	}
+	if _, err = LocalTime(2024, time.March, 10, 2, 30, time.Local); err == nil {
+		t.Fatal("LocalTime() with time.Local succeeded")
+	}
+}
+
+func TestScheduleOutId(t *testing.T) {
+	scheduler := newTestScheduler(t, NewMemoryStore(), &recordingDispatcher{})
+	req := request("13800000001")
+	req.OutId = "order-1"
+	id, err := scheduler.ScheduleAfter(req, time.Hour)
+	if err != nil || id != "order-1" {
+		t.Fatalf("Schedule() = %s, %v, want order-1", id, err)
+	}
+	if _, err = scheduler.ScheduleAfter(req, time.Hour); !errors.Is(err, ErrExists) {
+		t.Fatalf("second Schedule() = %v, want ErrExists", err)
+	}
+	req.IdempotencyKey = "order-2"
+	if _, err = scheduler.ScheduleAfter(req, time.Hour); err == nil {
+		t.Fatal("Schedule() with mismatched OutId succeeded")
+	}
+}
+
+func TestScheduleOutIdAcceptedByOutbox(t *testing.T) {
+	outbox, err := sms_outbox.New(sms_outbox.NewMemoryStore(), sms_outbox.Config{
+		Sender: func(context.Context, alibaba.SendRequest) (int32, third_party_tool_library.ResponseResult, error) {
+			return 200, third_party_tool_library.ResponseResult{}, nil
+		},
+		PollInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close(context.Background())
+	scheduler := newTestScheduler(t, NewMemoryStore(), outbox)
+	req := request("13800000001")
+	req.OutId = "order-1"
+	id, err := scheduler.ScheduleAfter(req, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := waitStatus(t, scheduler, id, JobDispatched)
+	if job.LastError != "" {
+		t.Fatalf("LastError = %s", job.LastError)
+	}
+	if _, err = outbox.Get("order-1"); err != nil {
+		t.Fatalf("outbox.Get(order-1) = %v", err)
+	}
+}
+
+func TestBoltStoreSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	store, err := OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	jobs := []Job{
+		{ID: "later", At: now.Add(time.Hour), Status: JobScheduled, TimeZone: "UTC"},
+		{ID: "due-2", At: now.Add(-time.Minute), Status: JobScheduled, TimeZone: "UTC"},
+		{ID: "due-1", At: now.Add(-time.Hour), Status: JobScheduled, TimeZone: "UTC"},
+		{ID: "canceled", At: now.Add(-time.Hour), Status: JobCanceled, TimeZone: "UTC"},
+	}
+	for _, job := range jobs {
+		if err = store.Create(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = store.Create(jobs[0]); !errors.Is(err, ErrExists) {
+		t.Fatalf("Create() duplicate = %v, want ErrExists", err)
+	}
+	// 改期后旧的索引被删除
+	rescheduled := jobs[0]
+	rescheduled.At = now.Add(-30 * time.Minute)
+	if err = store.Update(rescheduled); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if store, err = OpenBoltStore(path); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	due, err := store.Due(now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, job := range due {
+		ids = append(ids, job.ID)
+	}
+	want := []string{"due-1", "later", "due-2"}
+	if len(ids) != len(want) || ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] {
+		t.Fatalf("Due() = %v, want %v", ids, want)
+	}
+	if due, _ = store.Due(now, 1); len(due) != 1 {
+		t.Fatalf("Due(limit 1) returned %d jobs", len(due))
+	}
+	if _, err = store.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(missing) = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,99 @@
+package sms_scheduler
+
+import (
+	"errors"
+	"time"
+
+	"third_party_tool_library/alibaba"
+	"third_party_tool_library/alibaba/sms/internal/sms_store"
+)
+
+var (
+	// ErrNotFound 定时任务不存在
+	ErrNotFound = errors.New("定时任务不存在")
+	// ErrExists 相同 ID 的定时任务已存在
+	ErrExists = errors.New("相同 ID 的定时任务已存在")
+)
+
+// JobStatus 定时任务状态
+type JobStatus string
+
+const (
+	// JobScheduled 等待发送
+	JobScheduled JobStatus = "scheduled"
+	// JobDispatched 已到时间并交给发件箱发送，发送状态见发件箱中 ID 相同的消息
+	JobDispatched JobStatus = "dispatched"
+	// JobCanceled 已取消
+	JobCanceled JobStatus = "canceled"
+	// JobMissed 错过了发送时间（例如进程停止期间到期）且超过 Config.MaxLateness，不再发送
+	JobMissed JobStatus = "missed"
+)
+
+// Job 定时发送任务
+type Job struct {
+	// 任务 ID，同时作为幂等键和发件箱中的消息 ID
+	ID string `json:"id"`
+	// 发送请求
+	Request alibaba.SendRequest `json:"request"`
+	// 发送时间
+	At time.Time `json:"at"`
+	// 发送时间所在的时区，例如 Asia/Shanghai，用于 LocalAt 显示和按当地时间改期
+	TimeZone string `json:"time_zone"`
+	// 状态
+	Status JobStatus `json:"status"`
+	// 最近一次交给发件箱失败的原因
+	LastError string `json:"last_error"`
+	// 创建时间
+	CreatedAt time.Time `json:"created_at"`
+	// 更新时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LocalAt 发送时间在任务时区中的当地时间
+func (j Job) LocalAt() (time.Time, error) {
+	loc, err := time.LoadLocation(j.TimeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return j.At.In(loc), nil
+}
+
+// Store 定时任务存储
+/**
+ * 默认提供基于 bbolt 文件的 BoltStore 和仅用于测试的 MemoryStore
+ */
+type Store interface {
+	// Create 写入新任务，相同 ID 的任务已存在时返回 ErrExists
+	Create(job Job) error
+	// Update 更新任务，任务不存在时返回 ErrNotFound
+	Update(job Job) error
+	// Get 按 ID 查询任务，任务不存在时返回 ErrNotFound
+	Get(id string) (Job, error)
+	// Due 查询等待发送且到了发送时间的任务，按发送时间升序，最多 limit 个
+	Due(now time.Time, limit int) ([]Job, error)
+	// Close 关闭存储
+	Close() error
+}
+
+// 定时任务的存储方式：等待发送的任务按发送时间索引
+var jobSchema = sms_store.Schema[Job]{
+	Name:  "定时任务",
+	Items: []byte("jobs"),
+	Index: []byte("schedule"),
+	ID:    func(job Job) string { return job.ID },
+	Due: func(job Job) (time.Time, bool) {
+		return job.At, job.Status == JobScheduled
+	},
+	ErrExists:   ErrExists,
+	ErrNotFound: ErrNotFound,
+}
+
+// MemoryStore 内存中的定时任务存储，进程退出后任务丢失，仅用于测试
+type MemoryStore struct {
+	*sms_store.MemoryStore[Job]
+}
+
+// NewMemoryStore 创建内存中的定时任务存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{MemoryStore: sms_store.NewMemoryStore(jobSchema)}
+}
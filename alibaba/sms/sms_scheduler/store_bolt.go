@@ -0,0 +1,29 @@
+package sms_scheduler
+
+import (
+	"fmt"
+
+	"third_party_tool_library/alibaba/sms/internal/sms_store"
+)
+
+// BoltStore 基于 bbolt 文件的定时任务存储，同一个文件只能被一个进程打开
+/**
+ * 文件中 jobs 保存定时任务，键为任务 ID；schedule 为等待发送的任务索引，键为 8 字节的发送时间（UnixNano，大端序）加任务 ID
+ */
+type BoltStore struct {
+	*sms_store.BoltStore[Job]
+}
+
+// OpenBoltStore
+/** 打开定时任务文件，不存在时创建
+ * @param path 文件路径
+ * @return *BoltStore 定时任务存储，使用完毕后调用 Close
+ * @return error 文件已被其他进程打开时等待 1 秒后返回超时错误
+ */
+func OpenBoltStore(path string) (*BoltStore, error) {
+	store, err := sms_store.OpenBoltStore(path, jobSchema)
+	if err != nil {
+		return nil, fmt.Errorf("打开定时任务文件 %s 失败：%w", path, err)
+	}
+	return &BoltStore{BoltStore: store}, nil
+}
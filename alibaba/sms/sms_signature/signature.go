@@ -1,14 +1,12 @@
 package sms_signature
 
 import (
-	"errors"
-	"strings"
+	"context"
 
 	"third_party_tool_library"
 	"third_party_tool_library/alibaba"
 
 	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
-	"github.com/alibabacloud-go/tea/tea"
 )
 
 // AddSmsSignature
@@ -29,47 +27,20 @@ import (
  * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
 */
 func AddSmsSignature(accessKeyId, accessKeySecret, signName, remark string, signSource, signType int32, signFileList []*dysmsapi20170525.AddSmsSignRequestSignFileList) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, error error) {
-	if signSource < 0 || signSource > 5 {
-		return 400, third_party_tool_library.ResponseResult{}, errors.New("短信签名来源不规范：0：企事业单位的全称或简称。\n1：工信部备案网站的全称或简称。\n2：App 应用的全称或简称。\n3：公众号或小程序的全称或简称。\n4：电商平台店铺名的全称或简称。\n5：商标名的全称或简称。")
-	}
-	if signType < 1 || signType > 2 {
-		return 400, third_party_tool_library.ResponseResult{}, errors.New("短信签名类型不规范：0：验证码\n1：通用")
-	}
-	if signName != "" {
-		signName = strings.Trim(signName, " ")
-		if len(signName) > 12 {
-			return 400, third_party_tool_library.ResponseResult{}, errors.New("短信签名名称长度不能超过12个字符")
-		}
-	} else {
-		return 400, third_party_tool_library.ResponseResult{}, errors.New("短信签名名称不能为空")
-	}
+	return AddSmsSignatureContext(context.Background(), accessKeyId, accessKeySecret, signName, remark, signSource, signType, signFileList)
+}
 
-	remark = strings.Trim(remark, " ")
-	if len(remark) > 200 {
-		return 400, third_party_tool_library.ResponseResult{}, errors.New("短信签名申请说明，长度不能超过 200 个字符")
-	}
-	if len(signFileList) < 0 {
-		return 400, third_party_tool_library.ResponseResult{}, errors.New("短信签名申请证明文件不能为空")
-	}
+// AddSmsSignatureContext
+/** 申请短信签名，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 AddSmsSignature
+ */
+func AddSmsSignatureContext(ctx context.Context, accessKeyId, accessKeySecret, signName, remark string, signSource, signType int32, signFileList []*dysmsapi20170525.AddSmsSignRequestSignFileList) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, _err error) {
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, _err
 	}
-	// 创建并初始化发送信息对象
-
-	result, err := client.AddSmsSign(&dysmsapi20170525.AddSmsSignRequest{
-		SignName:     &signName,
-		Remark:       &remark,
-		SignSource:   &signSource,
-		SignType:     &signType,
-		SignFileList: signFileList,
-	})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-	return tea.Int32Value(result.StatusCode), resp, nil
+	return client.AddSmsSignatureContext(ctx, signName, remark, signSource, signType, signFileList)
 }
 
 // QuerySmsSignList
@@ -84,24 +55,20 @@ func AddSmsSignature(accessKeyId, accessKeySecret, signName, remark string, sign
  * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
  */
 func QuerySmsSignList(accessKeyId, accessKeySecret string, pageIndex, pageSize int32) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, smsSignList []*dysmsapi20170525.QuerySmsSignListResponseBodySmsSignList, error error) {
-	if pageIndex <= 0 {
-		pageIndex = 1
-	}
-	if pageSize < 10 {
-		pageSize = 10
-	}
+	return QuerySmsSignListContext(context.Background(), accessKeyId, accessKeySecret, pageIndex, pageSize)
+}
+
+// QuerySmsSignListContext
+/** 查询短信签名列表，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 QuerySmsSignList
+ */
+func QuerySmsSignListContext(ctx context.Context, accessKeyId, accessKeySecret string, pageIndex, pageSize int32) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, smsSignList []*dysmsapi20170525.QuerySmsSignListResponseBodySmsSignList, _err error) {
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, nil, _err
 	}
-	result, err := client.QuerySmsSignList(&dysmsapi20170525.QuerySmsSignListRequest{PageIndex: &pageIndex, PageSize: &pageSize})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, nil, _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-
-	return tea.Int32Value(result.StatusCode), resp, result.Body.SmsSignList, nil
+	return client.QuerySmsSignListContext(ctx, pageIndex, pageSize)
 }
 
 // ModifySmsSign
@@ -122,31 +89,20 @@ func QuerySmsSignList(accessKeyId, accessKeySecret string, pageIndex, pageSize i
  * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
 */
 func ModifySmsSign(accessKeyId, accessKeySecret, signName, remark string, signSource, signType int32, signFileList []*dysmsapi20170525.ModifySmsSignRequestSignFileList) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, error error) {
-	if signSource < 0 || signSource > 5 {
-		return 400, third_party_tool_library.ResponseResult{}, errors.New("短信签名来源不规范：0：企事业单位的全称或简称。\n1：工信部备案网站的全称或简称。\n2：App 应用的全称或简称。\n3：公众号或小程序的全称或简称。\n4：电商平台店铺名的全称或简称。\n5：商标名的全称或简称。")
-	}
-	if signType < 1 || signType > 2 {
-		return 400, third_party_tool_library.ResponseResult{}, errors.New("短信签名类型不规范：0：验证码\n1：通用")
-	}
+	return ModifySmsSignContext(context.Background(), accessKeyId, accessKeySecret, signName, remark, signSource, signType, signFileList)
+}
+
+// ModifySmsSignContext
+/** 修改短信签名，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 ModifySmsSign
+ */
+func ModifySmsSignContext(ctx context.Context, accessKeyId, accessKeySecret, signName, remark string, signSource, signType int32, signFileList []*dysmsapi20170525.ModifySmsSignRequestSignFileList) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, _err error) {
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, _err
 	}
-	// 创建并初始化发送信息对象
-
-	result, err := client.ModifySmsSign(&dysmsapi20170525.ModifySmsSignRequest{
-		SignName:     &signName,
-		Remark:       &remark,
-		SignSource:   &signSource,
-		SignType:     &signType,
-		SignFileList: signFileList,
-	})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-	return tea.Int32Value(result.StatusCode), resp, nil
+	return client.ModifySmsSignContext(ctx, signName, remark, signSource, signType, signFileList)
 }
 
 // DeleteSmsSign
@@ -159,22 +115,20 @@ func ModifySmsSign(accessKeyId, accessKeySecret, signName, remark string, signSo
  * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
  */
 func DeleteSmsSign(accessKeyId, accessKeySecret, signName string) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, error error) {
-	if signName == "" {
-		return 400, third_party_tool_library.ResponseResult{}, errors.New("短信签名名称不能为空")
-	}
+	return DeleteSmsSignContext(context.Background(), accessKeyId, accessKeySecret, signName)
+}
+
+// DeleteSmsSignContext
+/** 删除短信签名，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 DeleteSmsSign
+ */
+func DeleteSmsSignContext(ctx context.Context, accessKeyId, accessKeySecret, signName string) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, _err error) {
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, _err
 	}
-	result, err := client.DeleteSmsSign(&dysmsapi20170525.DeleteSmsSignRequest{
-		SignName: &signName,
-	})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-	return tea.Int32Value(result.StatusCode), resp, nil
+	return client.DeleteSmsSignContext(ctx, signName)
 }
 
 // QuerySmsSign
@@ -192,20 +146,18 @@ func DeleteSmsSign(accessKeyId, accessKeySecret, signName string) (httpStatusCod
  * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
 */
 func QuerySmsSign(accessKeyId, accessKeySecret, signName string) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, auditStatus int32, reason string, error error) {
-	if signName == "" {
-		return 400, third_party_tool_library.ResponseResult{}, -1, "", errors.New("短信签名名称不能为空")
-	}
+	return QuerySmsSignContext(context.Background(), accessKeyId, accessKeySecret, signName)
+}
+
+// QuerySmsSignContext
+/** 查询短信签名申请状态，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 QuerySmsSign
+ */
+func QuerySmsSignContext(ctx context.Context, accessKeyId, accessKeySecret, signName string) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, auditStatus int32, reason string, _err error) {
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, -1, "", _err
 	}
-	result, err := client.QuerySmsSign(&dysmsapi20170525.QuerySmsSignRequest{
-		SignName: &signName,
-	})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, -1, "", _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-	return tea.Int32Value(result.StatusCode), resp, tea.Int32Value(result.Body.SignStatus), tea.StringValue(result.Body.Reason), nil
+	return client.QuerySmsSignContext(ctx, signName)
 }
@@ -1,13 +1,12 @@
 package sms_template
 
 import (
-	"errors"
+	"context"
 
 	"third_party_tool_library"
 	"third_party_tool_library/alibaba"
 
 	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
-	"github.com/alibabacloud-go/tea/tea"
 )
 
 // AddSmsTemplate
@@ -28,33 +27,19 @@ import (
  * @return templateCode 短信模板 Code
 */
 func AddSmsTemplate(accessKeyId, accessKeySecret, templateName, templateContent, remark string, templateType int32) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, templateCode string, error error) {
-	if templateName == "" {
-		return 500, third_party_tool_library.ResponseResult{}, "", errors.New("短信模板名称不能为空")
-	}
-	if templateContent == "" {
-		return 500, third_party_tool_library.ResponseResult{}, "", errors.New("短信模板内容不能为空")
-	}
-	if remark == "" || len(remark) > 100 {
-		return 500, third_party_tool_library.ResponseResult{}, "", errors.New("短信模板申请说明不能为空，且不能超过100个字符")
-	}
-	if templateType < 0 || templateType > 3 {
-		return 500, third_party_tool_library.ResponseResult{}, "", errors.New("短信类型不规范：0：验证码。\n1：短信通知。\n2：推广短信。\n3：国际/港澳台消息。")
-	}
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	return AddSmsTemplateContext(context.Background(), accessKeyId, accessKeySecret, templateName, templateContent, remark, templateType)
+}
+
+// AddSmsTemplateContext
+/** 申请短信模板，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 AddSmsTemplate
+ */
+func AddSmsTemplateContext(ctx context.Context, accessKeyId, accessKeySecret, templateName, templateContent, remark string, templateType int32) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, templateCode string, _err error) {
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, "", _err
 	}
-	result, err := client.AddSmsTemplate(&dysmsapi20170525.AddSmsTemplateRequest{
-		TemplateName:    &templateName,
-		TemplateContent: &templateContent,
-		TemplateType:    &templateType,
-		Remark:          &remark,
-	})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, "", _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-	return tea.Int32Value(result.StatusCode), resp, tea.StringValue(result.Body.TemplateCode), nil
+	return client.AddSmsTemplateContext(ctx, templateName, templateContent, remark, templateType)
 }
 
 // QuerySmsTemplateList
@@ -69,24 +54,20 @@ func AddSmsTemplate(accessKeyId, accessKeySecret, templateName, templateContent,
  * @return smsTemplateList 短信模板列表。
  */
 func QuerySmsTemplateList(accessKeyId, accessKeySecret string, pageIndex, pageSize int32) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, smsTemplateList []*dysmsapi20170525.QuerySmsTemplateListResponseBodySmsTemplateList, error error) {
-	if pageIndex <= 0 {
-		pageIndex = 1
-	}
-	if pageSize < 10 {
-		pageSize = 10
-	}
+	return QuerySmsTemplateListContext(context.Background(), accessKeyId, accessKeySecret, pageIndex, pageSize)
+}
+
+// QuerySmsTemplateListContext
+/** 查询短信模板列表，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 QuerySmsTemplateList
+ */
+func QuerySmsTemplateListContext(ctx context.Context, accessKeyId, accessKeySecret string, pageIndex, pageSize int32) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, smsTemplateList []*dysmsapi20170525.QuerySmsTemplateListResponseBodySmsTemplateList, _err error) {
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, nil, _err
 	}
-	result, err := client.QuerySmsTemplateList(&dysmsapi20170525.QuerySmsTemplateListRequest{PageIndex: &pageIndex, PageSize: &pageSize})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, nil, _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-
-	return tea.Int32Value(result.StatusCode), resp, result.Body.SmsTemplateList, nil
+	return client.QuerySmsTemplateListContext(ctx, pageIndex, pageSize)
 }
 
 // QuerySmsTemplate
@@ -107,21 +88,20 @@ func QuerySmsTemplateList(accessKeyId, accessKeySecret string, pageIndex, pageSi
 						如果审核状态为审核未通过，参数 Reason 显示审核的具体原因。
 */
 func QuerySmsTemplate(accessKeyId, accessKeySecret, templateCode string) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, templateStatus int32, reason string, error error) {
-	if templateCode == "" {
-		return 500, third_party_tool_library.ResponseResult{}, 0, "", errors.New("短信模板编码不能为空")
-	}
+	return QuerySmsTemplateContext(context.Background(), accessKeyId, accessKeySecret, templateCode)
+}
+
+// QuerySmsTemplateContext
+/** 查询短信模板的审核状态，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 QuerySmsTemplate
+ */
+func QuerySmsTemplateContext(ctx context.Context, accessKeyId, accessKeySecret, templateCode string) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, templateStatus int32, reason string, _err error) {
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, 0, "", _err
 	}
-	result, err := client.QuerySmsTemplate(&dysmsapi20170525.QuerySmsTemplateRequest{TemplateCode: &templateCode})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, 0, "", _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-
-	return tea.Int32Value(result.StatusCode), resp, tea.Int32Value(result.Body.TemplateStatus), tea.StringValue(result.Body.Reason), nil
+	return client.QuerySmsTemplateContext(ctx, templateCode)
 }
 
 // ModifySmsTemplate
@@ -143,31 +123,20 @@ func QuerySmsTemplate(accessKeyId, accessKeySecret, templateCode string) (httpSt
  * @return templateCode 短信模板 Code
 */
 func ModifySmsTemplate(accessKeyId, accessKeySecret, templateCode, templateName, templateContent, remark string, templateType int32) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, respTemplateCode string, error error) {
-	if templateType < 0 || templateType > 3 {
-		return 500, third_party_tool_library.ResponseResult{}, "", errors.New("短信类型不规范：0：验证码。\n1：短信通知。\n2：推广短信。\n3：国际/港澳台消息。")
-	}
-	if templateCode == "" {
-		return 500, third_party_tool_library.ResponseResult{}, "", errors.New("短信模板编码不能为空")
-	}
+	return ModifySmsTemplateContext(context.Background(), accessKeyId, accessKeySecret, templateCode, templateName, templateContent, remark, templateType)
+}
+
+// ModifySmsTemplateContext
+/** 修改审核未通过的短信模板，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 ModifySmsTemplate
+ */
+func ModifySmsTemplateContext(ctx context.Context, accessKeyId, accessKeySecret, templateCode, templateName, templateContent, remark string, templateType int32) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, respTemplateCode string, _err error) {
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, "", _err
 	}
-	// 创建并初始化发送信息对象
-
-	result, err := client.ModifySmsTemplate(&dysmsapi20170525.ModifySmsTemplateRequest{
-		TemplateCode:    &templateCode,
-		Remark:          &remark,
-		TemplateType:    &templateType,
-		TemplateContent: &templateContent,
-		TemplateName:    &templateName,
-	})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, "", _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-	return tea.Int32Value(result.StatusCode), resp, tea.StringValue(result.Body.TemplateCode), nil
+	return client.ModifySmsTemplateContext(ctx, templateCode, templateName, templateContent, remark, templateType)
 }
 
 // DeleteSmsTemplate
@@ -181,22 +150,18 @@ func ModifySmsTemplate(accessKeyId, accessKeySecret, templateCode, templateName,
  * @param templateCode 短信模板 Code
  */
 func DeleteSmsTemplate(accessKeyId, accessKeySecret, templateCode string) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, respTemplateCode string, error error) {
-	if templateCode == "" {
-		return 500, third_party_tool_library.ResponseResult{}, "", errors.New("短信模板编码不能为空")
-	}
+	return DeleteSmsTemplateContext(context.Background(), accessKeyId, accessKeySecret, templateCode)
+}
+
+// DeleteSmsTemplateContext
+/** 删除短信模板，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 DeleteSmsTemplate
+ */
+func DeleteSmsTemplateContext(ctx context.Context, accessKeyId, accessKeySecret, templateCode string) (httpStatusCode int32, _resultMsg third_party_tool_library.ResponseResult, respTemplateCode string, _err error) {
 	// 创建客户端对象
-	client, _err := alibaba.CreateClient(tea.String(accessKeyId), tea.String(accessKeySecret))
+	client, _err := alibaba.NewClient(accessKeyId, accessKeySecret)
 	if _err != nil {
 		return 500, third_party_tool_library.ResponseResult{}, "", _err
 	}
-	// 创建并初始化发送信息对象
-
-	result, err := client.DeleteSmsTemplate(&dysmsapi20170525.DeleteSmsTemplateRequest{
-		TemplateCode: &templateCode,
-	})
-	if err != nil {
-		return 500, third_party_tool_library.ResponseResult{}, "", _err
-	}
-	resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message}
-	return tea.Int32Value(result.StatusCode), resp, tea.StringValue(result.Body.TemplateCode), nil
+	return client.DeleteSmsTemplateContext(ctx, templateCode)
 }
@@ -0,0 +1,202 @@
+package alibaba
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"third_party_tool_library"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// DefaultBatchConcurrency SendBatch 默认同时发送的分片数
+const DefaultBatchConcurrency = 4
+
+// ChunkResult 分片的发送结果
+type ChunkResult struct {
+	// 分片序号，从 0 开始
+	Index int
+	// 分片中第一个接收对象在号码合法的接收对象中的下标
+	Offset int
+	// 分片中的接收对象个数
+	Size int
+	// 接口响应编码
+	StatusCode int32
+	// 响应对象
+	Result third_party_tool_library.ResponseResult
+	// 错误响应对象
+	Err error
+}
+
+// OK 分片是否发送成功（响应编码为 200 且业务编码为 OK）
+func (r *ChunkResult) OK() bool {
+	return r.Err == nil && r.StatusCode == 200 && tea.StringValue(r.Result.Code) == "OK"
+}
+
+// RecipientResult 接收对象的发送结果，与所在分片的结果一致
+type RecipientResult struct {
+	// 手机号码，号码合法时为规范化后的号码，不合法时为原始输入
+	PhoneNumber string
+	// 所在分片的序号，号码不合法未发送时为 -1
+	Chunk int
+	// 是否发送成功
+	OK bool
+	// 业务编码，例如 OK、isv.MOBILE_NUMBER_ILLEGAL
+	Code string
+	// 业务信息
+	Message string
+	// 所在分片的发送回执 ID，用于查询发送状态（QuerySendDetails）
+	BizId string
+	// 所在分片的请求 ID
+	RequestId string
+	// 所在分片的请求次数（包含重试）
+	Attempts int
+	// 错误响应对象，号码不合法时为 *PhoneNumberError
+	Err error
+}
+
+// BatchResult 分片发送的结果汇总
+type BatchResult struct {
+	// 每个分片的结果，按分片序号排列
+	Chunks []ChunkResult
+	// 每个接收对象的结果，与请求中的接收对象一一对应
+	Recipients []RecipientResult
+	// 发送成功的接收对象个数
+	Succeeded int
+	// 发送失败的接收对象个数，包含号码不合法的接收对象
+	Failed int
+	// 号码不合法、未发送的接收对象个数
+	Rejected int
+}
+
+// SendBatch 分片发送短信
+/**
+ * 接收对象个数不受限制：批量发送按每片 100 个号码、单发按每片 1000 个号码拆分，
+ * 每个分片中的号码、签名、模板参数保持一一对应，分片之间并发发送，部分分片失败不影响其他分片
+ * 号码不合法的接收对象（见 NormalizePhoneNumber）不会发送，在结果中单独报告，不影响其他号码
+ * 指定幂等键时每个分片单独去重（键为 幂等键#分片序号），重试时只发送之前未成功的分片
+ * @param req 发送请求
+ * @param concurrency 同时发送的分片数，小于等于 0 时使用 DefaultBatchConcurrency
+ * @param opts 单次调用的配置项，对每个分片生效
+ * @return int32 接口响应编码：全部成功为 200，部分成功为 207，全部失败时为第一个失败分片的响应编码，参数不合法或号码全部不合法时为 400
+ * @return BatchResult 每个分片和每个接收对象的发送结果
+ * @return error 参数不合法时的错误，分片的错误在 BatchResult 中
+ */
+func (c *Client) SendBatch(req SendRequest, concurrency int, opts ...CallOption) (int32, BatchResult, error) {
+	return c.SendBatchContext(context.Background(), req, concurrency, opts...)
+}
+
+// SendBatchContext
+/** 分片发送短信，ctx 被取消后尚未发送的分片不再发送，其结果为包装后的 ctx.Err()
+ * 参数与返回值同 SendBatch
+ */
+func (c *Client) SendBatchContext(ctx context.Context, req SendRequest, concurrency int, opts ...CallOption) (int32, BatchResult, error) {
+	resolved, err := c.resolveSendRequest(req)
+	if err != nil {
+		return 400, BatchResult{}, err
+	}
+	if code, err := c.checkTemplateParams(ctx, resolved); err != nil {
+		return code, BatchResult{}, err
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	chunks := resolved.chunks(resolved.Mode.maxRecipients())
+	result := BatchResult{Chunks: make([]ChunkResult, len(chunks))}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		chunkResult := &result.Chunks[i]
+		chunkResult.Index = i
+		chunkResult.Offset = i * resolved.Mode.maxRecipients()
+		chunkResult.Size = len(chunk.Recipients)
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			chunkResult.StatusCode, chunkResult.Err = 500, contextError(ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(chunk *resolvedSendRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			chunkResult.StatusCode, chunkResult.Result, chunkResult.Err = c.sendResolved(ctx, chunk, opts)
+		}(chunk)
+	}
+	wg.Wait()
+
+	result.Recipients = make([]RecipientResult, len(req.Recipients))
+	for _, rejected := range resolved.rejected {
+		result.Recipients[rejected.index] = RecipientResult{
+			PhoneNumber: req.Recipients[rejected.index].PhoneNumber,
+			Chunk:       -1,
+			Message:     rejected.err.Error(),
+			Err:         rejected.err,
+		}
+	}
+	result.Rejected = len(resolved.rejected)
+	result.Failed = result.Rejected
+	for i := range result.Chunks {
+		chunk := &result.Chunks[i]
+		ok := chunk.OK()
+		for j, recipient := range resolved.Recipients[chunk.Offset : chunk.Offset+chunk.Size] {
+			result.Recipients[resolved.indexes[chunk.Offset+j]] = RecipientResult{
+				PhoneNumber: recipient.PhoneNumber,
+				Chunk:       chunk.Index,
+				OK:          ok,
+				Code:        tea.StringValue(chunk.Result.Code),
+				Message:     tea.StringValue(chunk.Result.Message),
+				BizId:       tea.StringValue(chunk.Result.BizId),
+				RequestId:   tea.StringValue(chunk.Result.RequestId),
+				Attempts:    chunk.Result.Attempts,
+				Err:         chunk.Err,
+			}
+		}
+		if ok {
+			result.Succeeded += chunk.Size
+		} else {
+			result.Failed += chunk.Size
+		}
+	}
+	return result.statusCode(), result, nil
+}
+
+// 汇总的响应编码
+func (r *BatchResult) statusCode() int32 {
+	if r.Failed == 0 {
+		return 200
+	}
+	if r.Succeeded > 0 {
+		return 207
+	}
+	// 没有发送任何分片，号码全部不合法
+	if len(r.Chunks) == 0 {
+		return 400
+	}
+	for i := range r.Chunks {
+		if !r.Chunks[i].OK() && r.Chunks[i].StatusCode != 0 {
+			return r.Chunks[i].StatusCode
+		}
+	}
+	return 500
+}
+
+// 按 size 拆分为多个请求，每个分片中的号码、签名、下标保持一一对应
+func (r *resolvedSendRequest) chunks(size int) []*resolvedSendRequest {
+	chunks := make([]*resolvedSendRequest, 0, (len(r.Recipients)+size-1)/size)
+	for start := 0; start < len(r.Recipients); start += size {
+		end := start + size
+		if end > len(r.Recipients) {
+			end = len(r.Recipients)
+		}
+		chunk := &resolvedSendRequest{SendRequest: r.SendRequest, signNames: r.signNames[start:end], indexes: r.indexes[start:end]}
+		chunk.Recipients = r.Recipients[start:end]
+		if r.dedupKey != "" {
+			chunk.dedupKey = fmt.Sprintf("%s#%d", r.dedupKey, len(chunks))
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
@@ -0,0 +1,146 @@
+package alibaba
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func testPhones(n int) []string {
+	phones := make([]string, n)
+	for i := range phones {
+		phones[i] = fmt.Sprintf("138%08d", i)
+	}
+	return phones
+}
+
+func TestSendBatchChunks(t *testing.T) {
+	var mu sync.Mutex
+	chunks := map[string][]string{}
+	server := newSmsServer(t, func(params url.Values) map[string]string {
+		var phones, signNames []string
+		if err := json.Unmarshal([]byte(params.Get("PhoneNumberJson")), &phones); err != nil {
+			t.Error(err)
+		}
+		if err := json.Unmarshal([]byte(params.Get("SignNameJson")), &signNames); err != nil {
+			t.Error(err)
+		}
+		if len(signNames) != len(phones) {
+			t.Errorf("%d sign names for %d phones", len(signNames), len(phones))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		chunks[phones[0]] = phones
+		return okResponse("biz-" + phones[0])
+	})
+	client := newTestClient(t, server)
+
+	phones := testPhones(250)
+	statusCode, result, err := client.SendBatch(SendRequest{Mode: SendModeBatch, Recipients: Recipients(phones...), TemplateCode: "SMS_1"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != 200 || result.Succeeded != 250 || result.Failed != 0 {
+		t.Fatalf("SendBatch() = %d, succeeded %d, failed %d", statusCode, result.Succeeded, result.Failed)
+	}
+	if len(result.Chunks) != 3 || len(chunks) != 3 {
+		t.Fatalf("got %d chunks, %d requests, want 3", len(result.Chunks), len(chunks))
+	}
+	for i, size := range []int{100, 100, 50} {
+		chunk := result.Chunks[i]
+		if chunk.Index != i || chunk.Offset != i*100 || chunk.Size != size || !chunk.OK() {
+			t.Fatalf("chunk %d = %+v", i, chunk)
+		}
+		if sent := chunks[phones[chunk.Offset]]; len(sent) != size || sent[size-1] != phones[chunk.Offset+size-1] {
+			t.Fatalf("chunk %d sent %d phones", i, len(sent))
+		}
+	}
+	for i, recipient := range result.Recipients {
+		chunk := i / 100
+		if recipient.PhoneNumber != phones[i] || recipient.Chunk != chunk || !recipient.OK || recipient.BizId != "biz-"+phones[chunk*100] {
+			t.Fatalf("recipient %d = %+v", i, recipient)
+		}
+	}
+}
+
+func TestSendBatchPartialResults(t *testing.T) {
+	phones := testPhones(150)
+	server := newSmsServer(t, func(params url.Values) map[string]string {
+		var sent []string
+		_ = json.Unmarshal([]byte(params.Get("PhoneNumberJson")), &sent)
+		if sent[0] == phones[100] {
+			return map[string]string{"Code": "isv.BUSINESS_LIMIT_CONTROL", "Message": "触发流控"}
+		}
+		return okResponse("biz")
+	})
+	client := newTestClient(t, server)
+
+	recipients := Recipients(phones...)
+	// 不合法的号码不发送，单独报告
+	recipients = append(recipients[:1], append(Recipients("12345"), recipients[1:]...)...)
+	statusCode, result, err := client.SendBatch(SendRequest{Mode: SendModeBatch, Recipients: recipients, TemplateCode: "SMS_1"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != 207 || result.Succeeded != 100 || result.Failed != 51 || result.Rejected != 1 {
+		t.Fatalf("SendBatch() = %d, succeeded %d, failed %d, rejected %d", statusCode, result.Succeeded, result.Failed, result.Rejected)
+	}
+	if len(result.Recipients) != len(recipients) {
+		t.Fatalf("got %d recipient results, want %d", len(result.Recipients), len(recipients))
+	}
+	rejected := result.Recipients[1]
+	var phoneErr *PhoneNumberError
+	if rejected.PhoneNumber != "12345" || rejected.Chunk != -1 || rejected.OK || !errors.As(rejected.Err, &phoneErr) {
+		t.Fatalf("rejected recipient = %+v", rejected)
+	}
+	if r := result.Recipients[0]; !r.OK || r.Chunk != 0 {
+		t.Fatalf("recipient 0 = %+v", r)
+	}
+	if r := result.Recipients[100]; !r.OK || r.Chunk != 0 || r.PhoneNumber != phones[99] {
+		t.Fatalf("recipient 100 = %+v", r)
+	}
+	if r := result.Recipients[101]; r.OK || r.Chunk != 1 || r.PhoneNumber != phones[100] || r.Code != "isv.BUSINESS_LIMIT_CONTROL" {
+		t.Fatalf("recipient 101 = %+v", r)
+	}
+}
+
+func TestSendBatchRetriesOnlyFailedChunks(t *testing.T) {
+	phones := testPhones(120)
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := newSmsServer(t, func(params url.Values) map[string]string {
+		var sent []string
+		_ = json.Unmarshal([]byte(params.Get("PhoneNumberJson")), &sent)
+		mu.Lock()
+		defer mu.Unlock()
+		requests[sent[0]]++
+		if params.Get("OutId") != "order-1" {
+			t.Errorf("OutId = %q, want order-1", params.Get("OutId"))
+		}
+		if sent[0] == phones[100] && requests[sent[0]] == 1 {
+			return map[string]string{"Code": "isp.SYSTEM_ERROR", "Message": "系统错误"}
+		}
+		return okResponse("biz-" + strconv.Itoa(len(sent)))
+	})
+	client := newTestClient(t, server, WithDedupStore(NewMemoryDedupStore(), 0))
+
+	req := SendRequest{Mode: SendModeBatch, Recipients: Recipients(phones...), TemplateCode: "SMS_1", IdempotencyKey: "order-1"}
+	if statusCode, _, err := client.SendBatch(req, 0); err != nil || statusCode != 207 {
+		t.Fatalf("first SendBatch() = %d, %v, want 207", statusCode, err)
+	}
+	statusCode, result, err := client.SendBatch(req, 0)
+	if err != nil || statusCode != 200 {
+		t.Fatalf("second SendBatch() = %d, %v, want 200", statusCode, err)
+	}
+	// 第一个分片已成功，重试时直接返回保存的结果
+	if requests[phones[0]] != 1 || requests[phones[100]] != 2 {
+		t.Fatalf("requests = %v", requests)
+	}
+	if result.Chunks[0].Result.Attempts != 0 || result.Chunks[1].Result.Attempts != 1 {
+		t.Fatalf("attempts = %d, %d, want 0, 1", result.Chunks[0].Result.Attempts, result.Chunks[1].Result.Attempts)
+	}
+}
@@ -0,0 +1,168 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"third_party_tool_library"
+
+	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// MaxSendDetailsPageSize QuerySendDetails 每页最多返回的记录数
+const MaxSendDetailsPageSize = 50
+
+// 短信接口返回的时间使用北京时间
+var chinaTimeZone = time.FixedZone("CST", 8*60*60)
+
+// SendStatus 短信发送状态
+type SendStatus int64
+
+const (
+	// SendStatusWaiting 等待回执
+	SendStatusWaiting SendStatus = 1
+	// SendStatusFailed 发送失败，失败原因见 SendDetail.ErrCode
+	SendStatusFailed SendStatus = 2
+	// SendStatusSuccess 发送成功
+	SendStatusSuccess SendStatus = 3
+)
+
+func (s SendStatus) String() string {
+	switch s {
+	case SendStatusWaiting:
+		return "waiting"
+	case SendStatusFailed:
+		return "failed"
+	case SendStatusSuccess:
+		return "success"
+	default:
+		return fmt.Sprintf("SendStatus(%d)", int64(s))
+	}
+}
+
+// SendDetailsQuery 短信发送记录查询条件
+type SendDetailsQuery struct {
+	// 接收短信的手机号码，会按 NormalizePhoneNumber 规范化
+	PhoneNumber string
+	// 发送日期，按北京时间取日期，支持查询最近 30 天的记录
+	SendDate time.Time
+	// 发送回执 ID（发送接口返回的 BizId），为空时查询该号码当天的所有记录
+	BizId string
+	// 每页查询的记录数，小于等于 0 或超过 MaxSendDetailsPageSize 时使用 MaxSendDetailsPageSize
+	PageSize int64
+}
+
+// SendDetail 短信发送记录
+type SendDetail struct {
+	// 接收短信的手机号码
+	PhoneNumber string
+	// 发送状态
+	SendStatus SendStatus
+	// 运营商返回的错误码，发送成功时为 DELIVERED
+	ErrCode string
+	// 短信模板 Code
+	TemplateCode string
+	// 短信内容
+	Content string
+	// 发送时间
+	SendDate time.Time
+	// 接收时间，未收到回执时为零值
+	ReceiveDate time.Time
+	// 外部流水扩展字段
+	OutId string
+}
+
+// QuerySendDetails 查询短信发送记录和发送状态
+/**
+ * 自动翻页查询所有记录，用于确认短信是否送达，例如排查“收不到验证码”的问题
+ * 错误码列表: https://help.aliyun.com/zh/sms/developer-reference/api-error-codes
+ * @param query 查询条件
+ * @param opts 单次调用的配置项，对每页的查询生效
+ * @return int32 接口响应编码，包含参数检测的错误编码（可以判断该编码是否为200）
+ * @return third_party_tool_library.ResponseResult 响应对象，查询失败时为失败页的响应
+ * @return []SendDetail 发送记录
+ * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
+ */
+func (c *Client) QuerySendDetails(query SendDetailsQuery, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, []SendDetail, error) {
+	return c.QuerySendDetailsContext(context.Background(), query, opts...)
+}
+
+// QuerySendDetailsContext
+/** 查询短信发送记录和发送状态，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 QuerySendDetails
+ */
+func (c *Client) QuerySendDetailsContext(ctx context.Context, query SendDetailsQuery, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, []SendDetail, error) {
+	phoneNumber, err := NormalizePhoneNumber(query.PhoneNumber)
+	if err != nil {
+		return 400, third_party_tool_library.ResponseResult{}, nil, err
+	}
+	if query.SendDate.IsZero() {
+		return 400, third_party_tool_library.ResponseResult{}, nil, errors.New("发送日期不能为空")
+	}
+	pageSize := query.PageSize
+	if pageSize <= 0 || pageSize > MaxSendDetailsPageSize {
+		pageSize = MaxSendDetailsPageSize
+	}
+	req := &dysmsapi20170525.QuerySendDetailsRequest{
+		PhoneNumber: tea.String(phoneNumber),
+		SendDate:    tea.String(query.SendDate.In(chinaTimeZone).Format("20060102")),
+		PageSize:    tea.Int64(pageSize),
+	}
+	if bizId := strings.TrimSpace(query.BizId); bizId != "" {
+		req.BizId = tea.String(bizId)
+	}
+
+	var details []SendDetail
+	for page := int64(1); ; page++ {
+		req.CurrentPage = tea.Int64(page)
+		result, err := invoke(ctx, c.runtimeOptions(opts), func(runtime *util.RuntimeOptions) (*dysmsapi20170525.QuerySendDetailsResponse, error) {
+			return c.smsClient().QuerySendDetailsWithOptions(req, runtime)
+		})
+		if err != nil {
+			return 500, third_party_tool_library.ResponseResult{}, details, err
+		}
+		resp := third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message, RequestId: result.Body.RequestId}
+		if tea.StringValue(result.Body.Code) != "OK" {
+			return tea.Int32Value(result.StatusCode), resp, details, nil
+		}
+		var records []*dysmsapi20170525.QuerySendDetailsResponseBodySmsSendDetailDTOsSmsSendDetailDTO
+		if result.Body.SmsSendDetailDTOs != nil {
+			records = result.Body.SmsSendDetailDTOs.SmsSendDetailDTO
+		}
+		for _, record := range records {
+			details = append(details, newSendDetail(record))
+		}
+		total, _ := strconv.ParseInt(tea.StringValue(result.Body.TotalCount), 10, 64)
+		if int64(len(records)) < pageSize || int64(len(details)) >= total {
+			return tea.Int32Value(result.StatusCode), resp, details, nil
+		}
+	}
+}
+
+func newSendDetail(record *dysmsapi20170525.QuerySendDetailsResponseBodySmsSendDetailDTOsSmsSendDetailDTO) SendDetail {
+	return SendDetail{
+		PhoneNumber:  tea.StringValue(record.PhoneNum),
+		SendStatus:   SendStatus(tea.Int64Value(record.SendStatus)),
+		ErrCode:      tea.StringValue(record.ErrCode),
+		TemplateCode: tea.StringValue(record.TemplateCode),
+		Content:      tea.StringValue(record.Content),
+		SendDate:     parseChinaTime(tea.StringValue(record.SendDate)),
+		ReceiveDate:  parseChinaTime(tea.StringValue(record.ReceiveDate)),
+		OutId:        tea.StringValue(record.OutId),
+	}
+}
+
+// 解析接口返回的北京时间，例如 2019-01-08 16:44:10，为空或格式不正确时返回零值
+func parseChinaTime(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", strings.TrimSpace(value), chinaTimeZone)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
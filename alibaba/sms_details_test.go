@@ -0,0 +1,213 @@
+package alibaba
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// 第 n 条发送记录
+func sendDetailRecord(n int) map[string]interface{} {
+	return map[string]interface{}{
+		"PhoneNum":     "13800000000",
+		"SendStatus":   n%3 + 1,
+		"ErrCode":      "DELIVERED",
+		"TemplateCode": "SMS_1",
+		"Content":      fmt.Sprintf("验证码%04d", n),
+		"SendDate":     "2024-01-08 16:44:10",
+		"ReceiveDate":  "2024-01-08 16:44:13",
+		"OutId":        strconv.Itoa(n),
+	}
+}
+
+// 模拟分页的发送记录：共 total 条，failPage 页返回业务错误（为 0 时不失败）
+func newDetailsServer(t *testing.T, total, failPage int, pages *[]url.Values) *Client {
+	t.Helper()
+	server := newApiServer(t, func(params url.Values) interface{} {
+		*pages = append(*pages, params)
+		page, _ := strconv.Atoi(params.Get("CurrentPage"))
+		size, _ := strconv.Atoi(params.Get("PageSize"))
+		if page == failPage {
+			return map[string]string{"Code": "isv.BUSINESS_LIMIT_CONTROL", "Message": "触发流控", "RequestId": "request-" + strconv.Itoa(page)}
+		}
+		var records []interface{}
+		for n := (page - 1) * size; n < page*size && n < total; n++ {
+			records = append(records, sendDetailRecord(n))
+		}
+		return map[string]interface{}{
+			"Code":              "OK",
+			"Message":           "OK",
+			"RequestId":         "request-" + strconv.Itoa(page),
+			"TotalCount":        strconv.Itoa(total),
+			"SmsSendDetailDTOs": map[string]interface{}{"SmsSendDetailDTO": records},
+		}
+	})
+	return newTestClient(t, server)
+}
+
+func TestQuerySendDetailsPagination(t *testing.T) {
+	tests := []struct {
+		total int
+		pages int
+	}{
+		// 最后一页不满一页时停止
+		{total: 5, pages: 3},
+		// 记录数恰好是页数的整数倍时，取到 TotalCount 条后停止
+		{total: 6, pages: 3},
+		{total: 0, pages: 1},
+	}
+	for _, tt := range tests {
+		var pages []url.Values
+		client := newDetailsServer(t, tt.total, 0, &pages)
+		sendDate := time.Date(2024, 1, 8, 20, 0, 0, 0, time.UTC)
+		statusCode, resp, details, err := client.QuerySendDetails(SendDetailsQuery{PhoneNumber: "+86 138 0000 0000", SendDate: sendDate, BizId: " biz ", PageSize: 2})
+		if err != nil || statusCode != 200 || *resp.Code != "OK" {
+			t.Fatalf("total %d: QuerySendDetails() = %d, %+v, %v", tt.total, statusCode, resp, err)
+		}
+		if len(details) != tt.total || len(pages) != tt.pages {
+			t.Fatalf("total %d: got %d details in %d pages, want %d pages", tt.total, len(details), len(pages), tt.pages)
+		}
+		for i, detail := range details {
+			if detail.OutId != strconv.Itoa(i) {
+				t.Fatalf("total %d: details[%d].OutId = %s", tt.total, i, detail.OutId)
+			}
+		}
+		// 发送日期按北京时间取日期
+		params := pages[0]
+		if params.Get("PhoneNumber") != "13800000000" || params.Get("SendDate") != "20240109" || params.Get("BizId") != "biz" || params.Get("CurrentPage") != "1" {
+			t.Fatalf("total %d: request = %v", tt.total, params)
+		}
+	}
+}
+
+func TestQuerySendDetailsPageSize(t *testing.T) {
+	for _, pageSize := range []int64{0, -1, MaxSendDetailsPageSize + 1} {
+		var pages []url.Values
+		client := newDetailsServer(t, 1, 0, &pages)
+		if _, _, _, err := client.QuerySendDetails(SendDetailsQuery{PhoneNumber: "13800000000", SendDate: time.Now(), PageSize: pageSize}); err != nil {
+			t.Fatal(err)
+		}
+		if size := pages[0].Get("PageSize"); size != strconv.Itoa(MaxSendDetailsPageSize) {
+			t.Errorf("PageSize %d sent as %s", pageSize, size)
+		}
+	}
+}
+
+func TestQuerySendDetailsFailingPage(t *testing.T) {
+	var pages []url.Values
+	client := newDetailsServer(t, 10, 3, &pages)
+	statusCode, resp, details, err := client.QuerySendDetails(SendDetailsQuery{PhoneNumber: "13800000000", SendDate: time.Now(), PageSize: 2})
+	// 失败页之前查到的记录仍然返回，响应为失败页的响应
+	if err != nil || statusCode != 200 || *resp.Code != "isv.BUSINESS_LIMIT_CONTROL" || *resp.RequestId != "request-3" {
+		t.Fatalf("QuerySendDetails() = %d, %+v, %v", statusCode, resp, err)
+	}
+	if len(details) != 4 || len(pages) != 3 {
+		t.Fatalf("got %d details in %d pages, want 4 details in 3 pages", len(details), len(pages))
+	}
+}
+
+func TestQuerySendDetailsTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("CurrentPage") != "1" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"Code":"ServiceUnavailable","Message":"服务不可用"}`)
+			return
+		}
+		records := []interface{}{sendDetailRecord(0), sendDetailRecord(1)}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"Code": "OK", "TotalCount": "10", "SmsSendDetailDTOs": map[string]interface{}{"SmsSendDetailDTO": records},
+		})
+	}))
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	// 请求失败之前查到的记录仍然返回
+	statusCode, _, details, err := client.QuerySendDetails(SendDetailsQuery{PhoneNumber: "13800000000", SendDate: time.Now(), PageSize: 2})
+	if err == nil || statusCode != 500 || len(details) != 2 {
+		t.Fatalf("QuerySendDetails() = %d, %d details, %v", statusCode, len(details), err)
+	}
+}
+
+func TestQuerySendDetailsInvalidQuery(t *testing.T) {
+	var pages []url.Values
+	client := newDetailsServer(t, 1, 0, &pages)
+	for _, query := range []SendDetailsQuery{
+		{PhoneNumber: "12345", SendDate: time.Now()},
+		{PhoneNumber: "+1 650 253 0000", SendDate: time.Now()},
+		{PhoneNumber: "13800000000"},
+	} {
+		if statusCode, _, _, err := client.QuerySendDetails(query); statusCode != 400 || err == nil {
+			t.Errorf("QuerySendDetails(%+v) = %d, %v, want 400", query, statusCode, err)
+		}
+	}
+	if len(pages) != 0 {
+		t.Fatalf("%d invalid queries were sent", len(pages))
+	}
+}
+
+func TestNewSendDetail(t *testing.T) {
+	var pages []url.Values
+	client := newDetailsServer(t, 3, 0, &pages)
+	_, _, details, err := client.QuerySendDetails(SendDetailsQuery{PhoneNumber: "13800000000", SendDate: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SendDetail{
+		PhoneNumber:  "13800000000",
+		SendStatus:   SendStatusWaiting,
+		ErrCode:      "DELIVERED",
+		TemplateCode: "SMS_1",
+		Content:      "验证码0000",
+		SendDate:     time.Date(2024, 1, 8, 8, 44, 10, 0, time.UTC),
+		ReceiveDate:  time.Date(2024, 1, 8, 8, 44, 13, 0, time.UTC),
+		OutId:        "0",
+	}
+	got := details[0]
+	if !got.SendDate.Equal(want.SendDate) || !got.ReceiveDate.Equal(want.ReceiveDate) {
+		t.Fatalf("dates = %v, %v", got.SendDate, got.ReceiveDate)
+	}
+	got.SendDate, got.ReceiveDate = want.SendDate, want.ReceiveDate
+	if got != want {
+		t.Fatalf("details[0] = %+v, want %+v", got, want)
+	}
+	for i, status := range []SendStatus{SendStatusWaiting, SendStatusFailed, SendStatusSuccess} {
+		if details[i].SendStatus != status {
+			t.Errorf("details[%d].SendStatus = %v, want %v", i, details[i].SendStatus, status)
+		}
+	}
+}
+
+func TestSendStatusString(t *testing.T) {
+	tests := map[SendStatus]string{
+		SendStatusWaiting: "waiting",
+		SendStatusFailed:  "failed",
+		SendStatusSuccess: "success",
+		SendStatus(9):     "SendStatus(9)",
+	}
+	for status, want := range tests {
+		if got := status.String(); got != want {
+			t.Errorf("SendStatus(%d).String() = %s, want %s", int64(status), got, want)
+		}
+	}
+}
+
+func TestParseChinaTime(t *testing.T) {
+	if got := parseChinaTime(" 2024-01-08 16:44:10 "); !got.Equal(time.Date(2024, 1, 8, 8, 44, 10, 0, time.UTC)) {
+		t.Errorf("parseChinaTime() = %v", got)
+	}
+	for _, value := range []string{"", "   ", "2024-01-08", "2024/01/08 16:44:10", "not a time"} {
+		if got := parseChinaTime(value); !got.IsZero() {
+			t.Errorf("parseChinaTime(%q) = %v, want zero", value, got)
+		}
+	}
+}
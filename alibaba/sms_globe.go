@@ -0,0 +1,294 @@
+package alibaba
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"third_party_tool_library"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	openapiutil "github.com/alibabacloud-go/openapi-util/service"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+const (
+	// DefaultGlobeEndpoint 国际/港澳台短信的默认服务地址
+	DefaultGlobeEndpoint = "dysmsapi.ap-southeast-1.aliyuncs.com"
+	// GlobeRegionId 国际/港澳台短信接口所在的地域
+	GlobeRegionId = "ap-southeast-1"
+	// 国际/港澳台短信接口的版本
+	globeApiVersion = "2018-05-01"
+)
+
+var (
+	// 字母数字发送方 ID：最多 11 个字符，至少包含一个字母
+	alphanumericSenderIdPattern = regexp.MustCompile(`^[A-Za-z0-9 ]{1,11}$`)
+	// 发送方 ID 中的字母
+	senderIdLetterPattern = regexp.MustCompile(`[A-Za-z]`)
+	// 数字发送方 ID：最多 15 位
+	numericSenderIdPattern = regexp.MustCompile(`^\d{1,15}$`)
+)
+
+// GlobeSendRequest 国际/港澳台短信发送请求
+/**
+ * Message 与 TemplateCode 二选一：指定 Message 时调用 SendMessageToGlobe 直接发送短信内容，
+ * 指定 TemplateCode 时调用 SendMessageWithTemplate 使用模板发送，例如：
+ *	client.SendGlobe(alibaba.GlobeSendRequest{
+ *		To:      "+65 9123 4567",
+ *		From:    "Alicloud",
+ *		Message: "Your code is 1234",
+ *	})
+ */
+type GlobeSendRequest struct {
+	// 接收号码，必须带国家码，例如 +65 9123 4567、0065 91234567、6591234567；
+	// 中国大陆手机号码可以不带国家码，会自动加上 86，因此国家码为 1 的号码必须带 + 或 00，见 GlobeNumber
+	To string
+	// 发送方 ID（Sender ID），为空时使用 WithSenderId 配置的默认 ID；
+	// 字母数字 ID 最多 11 个字符，数字 ID 最多 15 位，部分国家不支持自定义发送方 ID
+	From string
+	// 短信内容，与 TemplateCode 二选一
+	Message string
+	// 短信模板 Code，与 Message 二选一
+	TemplateCode string
+	// 模板参数，仅使用模板发送时可用
+	TemplateParams map[string]string
+	// 上行短信扩展码，仅使用模板发送时可用，可为空
+	SmsUpExtendCode string
+	// 短信类型：NOTIFY（通知）、MKT（推广），仅发送短信内容时可用，为空时为 NOTIFY
+	Type string
+	// 任务 ID，仅发送短信内容时可用，可为空
+	TaskId string
+	// 短信有效期（秒），超过有效期未送达的短信不再发送，为 0 时不限制
+	ValidityPeriod int64
+}
+
+// GlobeResult 国际/港澳台短信的发送结果
+type GlobeResult struct {
+	// 请求 ID
+	RequestId string
+	// 消息 ID，用于查询发送状态
+	MessageId string
+	// 接收号码
+	To string
+	// 发送方 ID
+	From string
+	// 计费条数
+	Segments string
+	// 号码所属的国家或地区，例如 Singapore、Hong Kong
+	Country string
+	// 号码所属的地区
+	Region string
+	// 号码所属的运营商
+	Carrier string
+}
+
+// 国际短信接口的响应
+type globeResponse struct {
+	StatusCode *int32             `json:"statusCode"`
+	Body       *globeResponseBody `json:"body"`
+}
+
+type globeResponseBody struct {
+	RequestId           *string `json:"RequestId"`
+	ResponseCode        *string `json:"ResponseCode"`
+	ResponseDescription *string `json:"ResponseDescription"`
+	MessageId           *string `json:"MessageId"`
+	To                  *string `json:"To"`
+	From                *string `json:"From"`
+	Segments            *string `json:"Segments"`
+	NumberDetail        *struct {
+		Country *string `json:"Country"`
+		Region  *string `json:"Region"`
+		Carrier *string `json:"Carrier"`
+	} `json:"NumberDetail"`
+}
+
+// GlobeNumber
+/** 将号码转换为国际短信接口使用的格式：带国家码、不带 + 的纯数字
+ * 号码先按 NormalizePhoneNumber 的规则规范化，只有不带国家码的中国大陆手机号码会加上国家码 86，
+ * 带 + 或 00 的号码保留原有的国家码，例如 +1 650 253 0000 返回 16502530000
+ * @param phoneNumber 手机号码
+ * @return string 国际短信接口使用的号码，例如 +65 9123 4567 返回 6591234567，13800000000 返回 8613800000000
+ * @return error 号码不合法时返回 *PhoneNumberError
+ */
+func GlobeNumber(phoneNumber string) (string, error) {
+	number, international, err := parsePhoneNumber(phoneNumber)
+	if err != nil {
+		return "", err
+	}
+	if !international && mainlandMobilePattern.MatchString(number) {
+		return "86" + number, nil
+	}
+	return number, nil
+}
+
+func validateSenderId(senderId string) error {
+	if senderId == "" {
+		return errors.New("发送方 ID 不能为空")
+	}
+	if numericSenderIdPattern.MatchString(senderId) {
+		return nil
+	}
+	if !alphanumericSenderIdPattern.MatchString(senderId) || !senderIdLetterPattern.MatchString(senderId) {
+		return fmt.Errorf("发送方 ID %s 不合法：字母数字 ID 最多 11 个字符且至少包含一个字母，数字 ID 最多 15 位", senderId)
+	}
+	return nil
+}
+
+// SendGlobe 发送国际/港澳台短信
+/**
+ * 使用国际站的 2018-05-01 版本接口（SendMessageToGlobe、SendMessageWithTemplate），服务地址见 WithGlobeEndpoint
+ * 错误码列表: https://www.alibabacloud.com/help/zh/sms/error-codes
+ * @param req 发送请求
+ * @param opts 单次调用的配置项，例如 CallReadTimeout
+ * @return int32 接口响应编码，包含参数检测的错误编码（可以判断该编码是否为200）
+ * @return third_party_tool_library.ResponseResult 响应对象，Code 为接口返回的 ResponseCode，成功时为 OK
+ * @return GlobeResult 发送结果，包含消息 ID 和号码所属的国家、运营商
+ * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）；
+ *               开启 WithFlowControl 时按 TemplateTypeGlobe 的规则限流，超过限流返回 429 和 *RateLimitError，
+ *               开启 WithCircuitBreaker 或 WithGlobeCircuitBreaker 时，国际短信接口熔断期间返回 503 和 ErrCircuitOpen
+ */
+func (c *Client) SendGlobe(req GlobeSendRequest, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, GlobeResult, error) {
+	return c.SendGlobeContext(context.Background(), req, opts...)
+}
+
+// SendGlobeContext
+/** 发送国际/港澳台短信，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 SendGlobe
+ */
+func (c *Client) SendGlobeContext(ctx context.Context, req GlobeSendRequest, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, GlobeResult, error) {
+	action, query, err := c.globeQuery(req)
+	if err != nil {
+		return 400, third_party_tool_library.ResponseResult{}, GlobeResult{}, err
+	}
+	if c.flow != nil {
+		if err := c.flow.AllowType(TemplateTypeGlobe, req.TemplateCode, query["To"].(string)); err != nil {
+			return 429, third_party_tool_library.ResponseResult{}, GlobeResult{}, err
+		}
+	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return 500, third_party_tool_library.ResponseResult{}, GlobeResult{}, err
+		}
+	}
+	var globeResult GlobeResult
+	statusCode, resp, err := c.withRetry(ctx, opts, withBreaker(c.globeBreaker, func(opts []CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+		statusCode, resp, result, err := c.sendGlobe(ctx, action, query, opts)
+		globeResult = result
+		return statusCode, resp, err
+	}))
+	return statusCode, resp, globeResult, err
+}
+
+// 调用一次国际短信接口并解析结果
+func (c *Client) sendGlobe(ctx context.Context, action string, query map[string]interface{}, opts []CallOption) (int32, third_party_tool_library.ResponseResult, GlobeResult, error) {
+	result, err := invoke(ctx, c.runtimeOptions(opts), func(runtime *util.RuntimeOptions) (*globeResponse, error) {
+		return c.callGlobe(action, query, runtime)
+	})
+	if err != nil {
+		return 500, third_party_tool_library.ResponseResult{}, GlobeResult{}, err
+	}
+	body := result.Body
+	if body == nil {
+		return 500, third_party_tool_library.ResponseResult{}, GlobeResult{}, errors.New("国际短信接口的响应为空")
+	}
+	globeResult := GlobeResult{
+		RequestId: tea.StringValue(body.RequestId),
+		MessageId: tea.StringValue(body.MessageId),
+		To:        tea.StringValue(body.To),
+		From:      tea.StringValue(body.From),
+		Segments:  tea.StringValue(body.Segments),
+	}
+	if body.NumberDetail != nil {
+		globeResult.Country = tea.StringValue(body.NumberDetail.Country)
+		globeResult.Region = tea.StringValue(body.NumberDetail.Region)
+		globeResult.Carrier = tea.StringValue(body.NumberDetail.Carrier)
+	}
+	resp := third_party_tool_library.ResponseResult{Code: body.ResponseCode, Message: body.ResponseDescription, RequestId: body.RequestId}
+	return tea.Int32Value(result.StatusCode), resp, globeResult, nil
+}
+
+// 校验请求，返回调用的接口名称和请求参数
+func (c *Client) globeQuery(req GlobeSendRequest) (string, map[string]interface{}, error) {
+	to, err := GlobeNumber(req.To)
+	if err != nil {
+		return "", nil, err
+	}
+	query := map[string]interface{}{"To": to}
+	if from := firstNonEmpty(strings.TrimSpace(req.From), c.senderId); from != "" {
+		if err := validateSenderId(from); err != nil {
+			return "", nil, err
+		}
+		query["From"] = from
+	}
+	if req.ValidityPeriod < 0 {
+		return "", nil, errors.New("短信有效期不能小于 0")
+	}
+	if req.ValidityPeriod > 0 {
+		query["ValidityPeriod"] = req.ValidityPeriod
+	}
+
+	switch {
+	case req.Message != "" && req.TemplateCode != "":
+		return "", nil, errors.New("Message 与 TemplateCode 不能同时指定")
+	case req.Message != "":
+		if req.TemplateParams != nil || req.SmsUpExtendCode != "" {
+			return "", nil, errors.New("发送短信内容时不能指定模板参数或上行短信扩展码")
+		}
+		query["Message"] = req.Message
+		if req.Type != "" {
+			query["Type"] = req.Type
+		}
+		if req.TaskId != "" {
+			query["TaskId"] = req.TaskId
+		}
+		return "SendMessageToGlobe", query, nil
+	case req.TemplateCode != "":
+		if req.Type != "" || req.TaskId != "" {
+			return "", nil, errors.New("使用模板发送时不能指定短信类型或任务 ID")
+		}
+		query["TemplateCode"] = req.TemplateCode
+		if req.TemplateParams != nil {
+			templateParam, err := json.Marshal(req.TemplateParams)
+			if err != nil {
+				return "", nil, err
+			}
+			query["TemplateParam"] = string(templateParam)
+		}
+		if req.SmsUpExtendCode != "" {
+			query["SmsUpExtendCode"] = req.SmsUpExtendCode
+		}
+		return "SendMessageWithTemplate", query, nil
+	default:
+		return "", nil, errors.New("短信内容与短信模板 Code 不能同时为空")
+	}
+}
+
+// 调用国际短信接口
+func (c *Client) callGlobe(action string, query map[string]interface{}, runtime *util.RuntimeOptions) (*globeResponse, error) {
+	params := &openapi.Params{
+		Action:      tea.String(action),
+		Version:     tea.String(globeApiVersion),
+		Protocol:    tea.String("HTTPS"),
+		Pathname:    tea.String("/"),
+		Method:      tea.String("POST"),
+		AuthType:    tea.String("AK"),
+		Style:       tea.String("RPC"),
+		ReqBodyType: tea.String("formData"),
+		BodyType:    tea.String("json"),
+	}
+	body, err := c.globeClient().CallApi(params, &openapi.OpenApiRequest{Query: openapiutil.Query(query)}, runtime)
+	if err != nil {
+		return nil, err
+	}
+	result := &globeResponse{}
+	if err := tea.Convert(body, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
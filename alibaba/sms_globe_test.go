@@ -0,0 +1,135 @@
+package alibaba
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGlobeNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"+1 415 555 0100", "14155550100"},
+		{"001 650 253 0000", "16502530000"},
+		{"+86 138 0000 0000", "8613800000000"},
+		{"0086 13800000000", "8613800000000"},
+		{"13800000000", "8613800000000"},
+		{"+65 9123 4567", "6591234567"},
+		{"6591234567", "6591234567"},
+	}
+	for _, tt := range tests {
+		got, err := GlobeNumber(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("GlobeNumber(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+	for _, input := range []string{"", "+86 12800000000", "12800000000", "+1 abc"} {
+		if _, err := GlobeNumber(input); !errors.Is(err, ErrInvalidPhoneNumber) {
+			t.Errorf("GlobeNumber(%q) error = %v, want ErrInvalidPhoneNumber", input, err)
+		}
+	}
+}
+
+func TestValidateSenderId(t *testing.T) {
+	tests := []struct {
+		senderId string
+		valid    bool
+	}{
+		{"Alibaba", true},
+		{"Shop 24", true},
+		{"12345678901", true},
+		{"123456789012345", true},
+		{"", false},
+		{"1234567890123456", false},
+		{"123 456", false},
+		{"    ", false},
+		{"AlibabaCloud", false},
+		{"Ali-baba", false},
+	}
+	for _, tt := range tests {
+		if err := validateSenderId(tt.senderId); (err == nil) != tt.valid {
+			t.Errorf("validateSenderId(%q) = %v, want valid %v", tt.senderId, err, tt.valid)
+		}
+	}
+}
+
+func TestSendGlobe(t *testing.T) {
+	var requests []globeRequest
+	server := newGlobeServer(t, map[string]interface{}{
+		"RequestId":           "request-1",
+		"ResponseCode":        "OK",
+		"ResponseDescription": "OK",
+		"MessageId":           "message-1",
+		"To":                  "14155550100",
+		"From":                "Alicloud",
+		"Segments":            "1",
+		"NumberDetail":        map[string]string{"Country": "United States", "Region": "California", "Carrier": "AT&T"},
+	}, &requests)
+	client := newTestClient(t, server, WithGlobeEndpoint(server.URL), WithSenderId("Alicloud"))
+
+	statusCode, resp, result, err := client.SendGlobe(GlobeSendRequest{To: "+1 415 555 0100", Message: "Your code is 1234", ValidityPeriod: 600})
+	if err != nil || statusCode != 200 || *resp.Code != "OK" || *resp.RequestId != "request-1" {
+		t.Fatalf("SendGlobe() = %d, %+v, %v", statusCode, resp, err)
+	}
+	want := GlobeResult{RequestId: "request-1", MessageId: "message-1", To: "14155550100", From: "Alicloud", Segments: "1",
+		Country: "United States", Region: "California", Carrier: "AT&T"}
+	if result != want {
+		t.Fatalf("GlobeResult = %+v, want %+v", result, want)
+	}
+	params := requests[0].params
+	if requests[0].action != "SendMessageToGlobe" || params.Get("To") != "14155550100" || params.Get("From") != "Alicloud" ||
+		params.Get("Message") != "Your code is 1234" || params.Get("ValidityPeriod") != "600" {
+		t.Fatalf("request = %s %v", requests[0].action, params)
+	}
+
+	// 使用模板发送，中国大陆号码加上国家码 86，请求中的发送方 ID 优先
+	_, _, _, err = client.SendGlobe(GlobeSendRequest{To: "13800000000", From: "Shop", TemplateCode: "SMS_1", TemplateParams: map[string]string{"code": "1234"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params = requests[1].params
+	if requests[1].action != "SendMessageWithTemplate" || params.Get("To") != "8613800000000" || params.Get("From") != "Shop" ||
+		params.Get("TemplateCode") != "SMS_1" || params.Get("TemplateParam") != `{"code":"1234"}` {
+		t.Fatalf("request = %s %v", requests[1].action, params)
+	}
+}
+
+func TestSendGlobeBusinessError(t *testing.T) {
+	var requests []globeRequest
+	server := newGlobeServer(t, map[string]interface{}{
+		"RequestId":           "request-1",
+		"ResponseCode":        "InvalidPhoneNumber",
+		"ResponseDescription": "invalid phone number",
+	}, &requests)
+	client := newTestClient(t, server, WithGlobeEndpoint(server.URL))
+
+	statusCode, resp, result, err := client.SendGlobe(GlobeSendRequest{To: "+65 9123 4567", Message: "hello"})
+	if err != nil || statusCode != 200 || *resp.Code != "InvalidPhoneNumber" || *resp.Message != "invalid phone number" || result.MessageId != "" {
+		t.Fatalf("SendGlobe() = %d, %+v, %+v, %v", statusCode, resp, result, err)
+	}
+}
+
+func TestSendGlobeInvalidRequest(t *testing.T) {
+	var requests []globeRequest
+	server := newGlobeServer(t, map[string]interface{}{"ResponseCode": "OK"}, &requests)
+	client := newTestClient(t, server, WithGlobeEndpoint(server.URL))
+
+	invalid := []GlobeSendRequest{
+		{To: "12345", Message: "hello"},
+		{To: "+65 9123 4567"},
+		{To: "+65 9123 4567", Message: "hello", TemplateCode: "SMS_1"},
+		{To: "+65 9123 4567", Message: "hello", TemplateParams: map[string]string{"code": "1234"}},
+		{To: "+65 9123 4567", TemplateCode: "SMS_1", Type: "MKT"},
+		{To: "+65 9123 4567", Message: "hello", From: "AlibabaCloud"},
+		{To: "+65 9123 4567", Message: "hello", ValidityPeriod: -1},
+	}
+	for _, req := range invalid {
+		if statusCode, _, _, err := client.SendGlobe(req); statusCode != 400 || err == nil {
+			t.Errorf("SendGlobe(%+v) = %d, %v, want 400", req, statusCode, err)
+		}
+	}
+	if len(requests) != 0 {
+		t.Fatalf("%d invalid requests were sent", len(requests))
+	}
+}
@@ -0,0 +1,195 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"third_party_tool_library"
+
+	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// ErrTemplateNotFound 模板名称未注册
+var ErrTemplateNotFound = errors.New("短信模板未注册")
+
+// Send 短信发送
+/**
+ * 错误码列表: https://help.aliyun.com/zh/sms/developer-reference/api-error-codes
+ * @param req 发送请求，单发时所有号码使用同一个签名和模板参数，批量发送时每个号码可以单独指定；
+ *            单发最多 1000 个号码，批量发送最多 100 个号码，超过时请使用 SendBatch
+ * @param opts 单次调用的配置项，例如 CallReadTimeout
+ * @return int32 接口响应编码，包含参数检测的错误编码（可以判断该编码是否为200）
+ * @return third_party_tool_library.ResponseResult 响应对象（第三方返回的响应信息都在里面，包含业务错误），
+ *               BizId 可用于 QuerySendDetails 查询发送状态，RequestId 用于向阿里云排查问题，
+ *               Attempts 为请求次数（见 WithRetryPolicy）
+ * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）；
+ *               单发时存在不合法的手机号码不发送，返回 400 和包装后的 *PhoneNumberError；
+ *               批量发送时跳过不合法的号码，其余号码照常发送，跳过的号码见 ResponseResult.Rejected，发送成功时响应编码为 207，
+ *               号码全部不合法时返回 400 和包装后的 *PhoneNumberError，
+ *               相同幂等键的短信正在发送或上一次发送结果未知时返回 409 和 ErrSendInProgress，
+ *               开启 WithFlowControl 时，超过本地限流返回 429 和 *RateLimitError，
+ *               开启 WithCircuitBreaker 时，熔断期间返回 503 和 ErrCircuitOpen，
+ *               开启 WithTemplateValidation 时，模板参数不一致返回 *TemplateParamError
+ */
+func (c *Client) Send(req SendRequest, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	return c.SendContext(context.Background(), req, opts...)
+}
+
+// SendContext
+/** 短信发送，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 请求已经发出时底层请求无法中止，返回的错误满足 errors.Is(err, ErrAbandoned)，短信可能仍会发出，不应直接重试
+ * 参数与返回值同 Send
+ */
+func (c *Client) SendContext(ctx context.Context, req SendRequest, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	resolved, err := c.resolveSendRequest(req)
+	if err != nil {
+		return 400, third_party_tool_library.ResponseResult{}, err
+	}
+	// 批量发送时跳过不合法的号码
+	if err := resolved.rejectedError(); err != nil && (resolved.Mode != SendModeBatch || len(resolved.Recipients) == 0) {
+		return 400, third_party_tool_library.ResponseResult{}, err
+	}
+	if limit := resolved.Mode.maxRecipients(); len(resolved.Recipients) > limit {
+		return 400, third_party_tool_library.ResponseResult{}, fmt.Errorf("%s 每次最多发送 %d 个号码，当前 %d 个，请使用 SendBatch 分批发送", resolved.Mode, limit, len(resolved.Recipients))
+	}
+	if code, err := c.checkTemplateParams(ctx, resolved); err != nil {
+		return code, third_party_tool_library.ResponseResult{}, err
+	}
+	statusCode, result, err := c.sendResolved(ctx, resolved, opts)
+	if len(resolved.rejected) > 0 {
+		result.Rejected = resolved.rejectedRecipients(req)
+		if err == nil && statusCode == 200 && tea.StringValue(result.Code) == "OK" {
+			statusCode = 207
+		}
+	}
+	return statusCode, result, err
+}
+
+// 发送已解析的请求
+func (c *Client) sendResolved(ctx context.Context, resolved *resolvedSendRequest, opts []CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	var send func(opts []CallOption) (int32, third_party_tool_library.ResponseResult, error)
+	if resolved.Mode == SendModeBatch {
+		sendBatchSmsRequest, err := resolved.sendBatchSmsRequest()
+		if err != nil {
+			return 400, third_party_tool_library.ResponseResult{}, err
+		}
+		send = func(opts []CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+			return c.batchSmsSend(ctx, sendBatchSmsRequest, c.runtimeOptions(opts))
+		}
+	} else {
+		sendSmsRequest, err := resolved.sendSmsRequest()
+		if err != nil {
+			return 400, third_party_tool_library.ResponseResult{}, err
+		}
+		send = func(opts []CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+			return c.singleSmsSend(ctx, sendSmsRequest, c.runtimeOptions(opts))
+		}
+	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return 500, third_party_tool_library.ResponseResult{}, err
+		}
+	}
+	return c.sendOnce(ctx, resolved.dedupKey, func() (int32, third_party_tool_library.ResponseResult, error) {
+		if err := c.allowFlow(resolved); err != nil {
+			return 429, third_party_tool_library.ResponseResult{}, err
+		}
+		return c.withRetry(ctx, opts, withBreaker(c.breaker, send))
+	})
+}
+
+// SmsSend 短信发送
+/**
+ * 批量发送使用的是同一个短信模板，手机号码、短信签名、模板中的参数、都是json串的方式，都是一一对应的，例如：phoneNumbers{"139xxx1","136xxx1"},signName{"xxx通知","xxx短信"}
+ * 单个短信发送时所有号码（逗号分隔）使用同一个签名和模板参数
+ * 参数会被解析为 SendRequest 后调用 Send，新代码建议直接使用 Send；批量发送时跳过不合法的号码，其余号码照常发送
+ * 错误码列表: https://help.aliyun.com/zh/sms/developer-reference/api-error-codes
+ * @param phoneNumbers 接收对象的手机号码
+ * @param signName 短信签名名称，为空时使用 WithSignName 配置的默认签名
+ * @param templateCode  短信模板编号
+ * @param templateParam 短信模板中的参数
+ * @param isBatchSend 是否进行批量发送
+ * @param opts 单次调用的配置项，例如 CallReadTimeout
+ * @return int32 接口响应编码，包含参数检测的错误编码（可以判断该编码是否为200）
+ * @return third_party_tool_library.ResponseResult 响应对象（第三方返回的响应信息都在里面，包含业务错误）
+ * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
+ */
+func (c *Client) SmsSend(phoneNumbers, signName, templateCode, templateParam string, isBatchSend bool, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	return c.SmsSendContext(context.Background(), phoneNumbers, signName, templateCode, templateParam, isBatchSend, opts...)
+}
+
+// SmsSendContext
+/** 短信发送，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 SmsSend
+ */
+func (c *Client) SmsSendContext(ctx context.Context, phoneNumbers, signName, templateCode, templateParam string, isBatchSend bool, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	req, err := parseSendRequest(phoneNumbers, signName, templateCode, templateParam, isBatchSend)
+	if err != nil {
+		return 400, third_party_tool_library.ResponseResult{}, err
+	}
+	return c.SendContext(ctx, req, opts...)
+}
+
+// SendTemplate 按模板名称发送短信
+/**
+ * 模板名称通过 WithTemplate 或配置文件注册，模板配置了签名时使用模板的签名，否则使用默认签名
+ * @param name 模板名称
+ * @param phoneNumbers 接收对象的手机号码，批量发送时为 JSON 数组
+ * @param templateParam 短信模板中的参数，批量发送时为 JSON 数组
+ * @param isBatchSend 是否进行批量发送
+ * @param opts 单次调用的配置项，例如 CallReadTimeout
+ * @return int32 接口响应编码，模板未注册时为 400
+ * @return third_party_tool_library.ResponseResult 响应对象（第三方返回的响应信息都在里面，包含业务错误）
+ * @return error 错误响应对象
+ */
+func (c *Client) SendTemplate(name, phoneNumbers, templateParam string, isBatchSend bool, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	return c.SendTemplateContext(context.Background(), name, phoneNumbers, templateParam, isBatchSend, opts...)
+}
+
+// SendTemplateContext
+/** 按模板名称发送短信，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 SendTemplate
+ */
+func (c *Client) SendTemplateContext(ctx context.Context, name, phoneNumbers, templateParam string, isBatchSend bool, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, error) {
+	req, err := parseSendRequest(phoneNumbers, "", "", templateParam, isBatchSend)
+	if err != nil {
+		return 400, third_party_tool_library.ResponseResult{}, err
+	}
+	req.Template = name
+	return c.SendContext(ctx, req, opts...)
+}
+
+// 发送单个短信
+func (c *Client) singleSmsSend(ctx context.Context, req *dysmsapi20170525.SendSmsRequest, runtime *util.RuntimeOptions) (int32, third_party_tool_library.ResponseResult, error) {
+	result, err := invoke(ctx, runtime, func(runtime *util.RuntimeOptions) (*dysmsapi20170525.SendSmsResponse, error) {
+		return c.smsClient().SendSmsWithOptions(req, runtime)
+	})
+	if err != nil {
+		return 500, third_party_tool_library.ResponseResult{}, err
+	}
+	return tea.Int32Value(result.StatusCode), third_party_tool_library.ResponseResult{
+		Code:      result.Body.Code,
+		Message:   result.Body.Message,
+		RequestId: result.Body.RequestId,
+		BizId:     result.Body.BizId,
+	}, nil
+}
+
+// 批量发送短信
+func (c *Client) batchSmsSend(ctx context.Context, req *dysmsapi20170525.SendBatchSmsRequest, runtime *util.RuntimeOptions) (int32, third_party_tool_library.ResponseResult, error) {
+	result, err := invoke(ctx, runtime, func(runtime *util.RuntimeOptions) (*dysmsapi20170525.SendBatchSmsResponse, error) {
+		return c.smsClient().SendBatchSmsWithOptions(req, runtime)
+	})
+	if err != nil {
+		return 500, third_party_tool_library.ResponseResult{}, err
+	}
+	return tea.Int32Value(result.StatusCode), third_party_tool_library.ResponseResult{
+		Code:      result.Body.Code,
+		Message:   result.Body.Message,
+		RequestId: result.Body.RequestId,
+		BizId:     result.Body.BizId,
+	}, nil
+}
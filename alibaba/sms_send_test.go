@@ -0,0 +1,54 @@
+package alibaba
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestSendBatchModeSkipsInvalidNumbers(t *testing.T) {
+	var sent []string
+	server := newSmsServer(t, func(params url.Values) map[string]string {
+		if err := json.Unmarshal([]byte(params.Get("PhoneNumberJson")), &sent); err != nil {
+			t.Error(err)
+		}
+		return okResponse("biz")
+	})
+	client := newTestClient(t, server)
+
+	statusCode, result, err := client.SmsSend(`["+86 138-0000-0000","12345","13900000000"]`, "", "SMS_1", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != 207 || len(sent) != 2 || sent[0] != "13800000000" || sent[1] != "13900000000" {
+		t.Fatalf("SmsSend() = %d, sent %v", statusCode, sent)
+	}
+	if len(result.Rejected) != 1 || result.Rejected[0].Index != 1 || result.Rejected[0].PhoneNumber != "12345" || result.Rejected[0].Reason == "" {
+		t.Fatalf("Rejected = %+v", result.Rejected)
+	}
+}
+
+func TestSendRejectsInvalidNumbers(t *testing.T) {
+	server := newSmsServer(t, func(url.Values) map[string]string {
+		t.Error("request was sent")
+		return okResponse("biz")
+	})
+	client := newTestClient(t, server)
+
+	// 单发时任何号码不合法都不发送
+	statusCode, _, err := client.Send(SendRequest{Recipients: Recipients("13800000000", "12345"), TemplateCode: "SMS_1"})
+	if statusCode != 400 || !errors.Is(err, ErrInvalidPhoneNumber) {
+		t.Fatalf("Send() = %d, %v, want 400 and ErrInvalidPhoneNumber", statusCode, err)
+	}
+	// 国家码为 1 的号码不能通过国内短信接口发送
+	statusCode, _, err = client.Send(SendRequest{Recipients: Recipients("+1 650 253 0000"), TemplateCode: "SMS_1"})
+	if statusCode != 400 || !errors.Is(err, ErrInvalidPhoneNumber) {
+		t.Fatalf("Send() = %d, %v, want 400 and ErrInvalidPhoneNumber", statusCode, err)
+	}
+	// 批量发送时号码全部不合法
+	statusCode, _, err = client.Send(SendRequest{Mode: SendModeBatch, Recipients: Recipients("12345", ""), TemplateCode: "SMS_1"})
+	if statusCode != 400 || !errors.Is(err, ErrInvalidPhoneNumber) {
+		t.Fatalf("Send() = %d, %v, want 400 and ErrInvalidPhoneNumber", statusCode, err)
+	}
+}
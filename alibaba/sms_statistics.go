@@ -0,0 +1,208 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"third_party_tool_library"
+
+	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// MaxSendStatisticsPageSize QuerySendStatistics 每页最多返回的记录数
+const MaxSendStatisticsPageSize = 50
+
+// SendScope 短信发送范围
+type SendScope int32
+
+const (
+	// SendScopeDomestic 国内短信
+	SendScopeDomestic SendScope = 1
+	// SendScopeGlobe 国际/港澳台短信
+	SendScopeGlobe SendScope = 2
+)
+
+func (s SendScope) String() string {
+	switch s {
+	case SendScopeDomestic:
+		return "domestic"
+	case SendScopeGlobe:
+		return "globe"
+	default:
+		return fmt.Sprintf("SendScope(%d)", int32(s))
+	}
+}
+
+// SendStatisticsQuery 短信发送统计查询条件
+type SendStatisticsQuery struct {
+	// 开始日期，按北京时间取日期
+	StartDate time.Time
+	// 结束日期（包含），按北京时间取日期
+	EndDate time.Time
+	// 发送范围，为 0 时查询国内短信
+	Scope SendScope
+	// 短信签名，为空时统计所有签名
+	SignName string
+	// 每页查询的记录数，小于等于 0 或超过 MaxSendStatisticsPageSize 时使用 MaxSendStatisticsPageSize
+	PageSize int32
+}
+
+// SendCounts 短信发送数量
+type SendCounts struct {
+	// 发送总条数
+	Submitted int64
+	// 发送成功的条数
+	Succeeded int64
+	// 发送失败的条数
+	Failed int64
+	// 等待回执的条数
+	Pending int64
+}
+
+func (c *SendCounts) add(other SendCounts) {
+	c.Submitted += other.Submitted
+	c.Succeeded += other.Succeeded
+	c.Failed += other.Failed
+	c.Pending += other.Pending
+}
+
+// DailySendStatistics 每天的短信发送数量
+type DailySendStatistics struct {
+	// 日期，北京时间零点
+	Date time.Time
+	SendCounts
+}
+
+// SendStatistics 短信发送统计
+type SendStatistics struct {
+	// 每天的发送数量，按日期升序排列，没有发送记录的日期不在其中
+	Days []DailySendStatistics
+	// 查询范围内的合计
+	Total SendCounts
+}
+
+// QuerySendStatistics 查询短信发送统计
+/**
+ * 自动翻页查询日期范围内每天的发送数量，并汇总合计，例如统计上个月的用量：
+ *	client.QuerySendStatistics(alibaba.SendStatisticsQuery{
+ *		StartDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local),
+ *		EndDate:   time.Date(2024, 5, 31, 0, 0, 0, 0, time.Local),
+ *	})
+ * @param query 查询条件
+ * @param opts 单次调用的配置项，对每页的查询生效
+ * @return int32 接口响应编码，包含参数检测的错误编码（可以判断该编码是否为200）
+ * @return third_party_tool_library.ResponseResult 响应对象，查询失败时为失败页的响应
+ * @return SendStatistics 发送统计
+ * @return error 错误响应对象（通常都是系统中的错误，业务错误不在其中，建议首先检测该对象是否 err == nil）
+ */
+func (c *Client) QuerySendStatistics(query SendStatisticsQuery, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, SendStatistics, error) {
+	return c.QuerySendStatisticsContext(context.Background(), query, opts...)
+}
+
+// QuerySendStatisticsContext
+/** 查询短信发送统计，ctx 被取消或超过截止时间时停止等待并返回包装后的 ctx.Err()
+ * 参数与返回值同 QuerySendStatistics
+ */
+func (c *Client) QuerySendStatisticsContext(ctx context.Context, query SendStatisticsQuery, opts ...CallOption) (int32, third_party_tool_library.ResponseResult, SendStatistics, error) {
+	if query.StartDate.IsZero() || query.EndDate.IsZero() {
+		return 400, third_party_tool_library.ResponseResult{}, SendStatistics{}, errors.New("开始日期和结束日期不能为空")
+	}
+	startDate := query.StartDate.In(chinaTimeZone).Format("20060102")
+	endDate := query.EndDate.In(chinaTimeZone).Format("20060102")
+	if startDate > endDate {
+		return 400, third_party_tool_library.ResponseResult{}, SendStatistics{}, errors.New("开始日期不能晚于结束日期")
+	}
+	scope := query.Scope
+	if scope == 0 {
+		scope = SendScopeDomestic
+	}
+	if scope != SendScopeDomestic && scope != SendScopeGlobe {
+		return 400, third_party_tool_library.ResponseResult{}, SendStatistics{}, fmt.Errorf("发送范围 %s 不合法", scope)
+	}
+	pageSize := query.PageSize
+	if pageSize <= 0 || pageSize > MaxSendStatisticsPageSize {
+		pageSize = MaxSendStatisticsPageSize
+	}
+	req := &dysmsapi20170525.QuerySendStatisticsRequest{
+		StartDate: tea.String(startDate),
+		EndDate:   tea.String(endDate),
+		IsGlobe:   tea.Int32(int32(scope)),
+		PageSize:  tea.Int32(pageSize),
+	}
+	if signName := strings.TrimSpace(query.SignName); signName != "" {
+		req.SignName = tea.String(signName)
+	}
+
+	days := make(map[string]*DailySendStatistics)
+	var statistics SendStatistics
+	var (
+		statusCode int32
+		resp       third_party_tool_library.ResponseResult
+		fetched    int64
+	)
+	for page := int32(1); ; page++ {
+		req.PageIndex = tea.Int32(page)
+		result, err := invoke(ctx, c.runtimeOptions(opts), func(runtime *util.RuntimeOptions) (*dysmsapi20170525.QuerySendStatisticsResponse, error) {
+			return c.smsClient().QuerySendStatisticsWithOptions(req, runtime)
+		})
+		if err != nil {
+			return 500, third_party_tool_library.ResponseResult{}, SendStatistics{}, err
+		}
+		statusCode = tea.Int32Value(result.StatusCode)
+		resp = third_party_tool_library.ResponseResult{Code: result.Body.Code, Message: result.Body.Message, RequestId: result.Body.RequestId}
+		if tea.StringValue(result.Body.Code) != "OK" {
+			return statusCode, resp, SendStatistics{}, nil
+		}
+		var (
+			records []*dysmsapi20170525.QuerySendStatisticsResponseBodyDataTargetList
+			total   int64
+		)
+		if result.Body.Data != nil {
+			records = result.Body.Data.TargetList
+			total = tea.Int64Value(result.Body.Data.TotalSize)
+		}
+		for _, record := range records {
+			date := tea.StringValue(record.SendDate)
+			day, ok := days[date]
+			if !ok {
+				day = &DailySendStatistics{Date: parseChinaDate(date)}
+				days[date] = day
+			}
+			day.add(SendCounts{
+				Submitted: tea.Int64Value(record.TotalCount),
+				Succeeded: tea.Int64Value(record.RespondedSuccessCount),
+				Failed:    tea.Int64Value(record.RespondedFailCount),
+				Pending:   tea.Int64Value(record.NoRespondedCount),
+			})
+		}
+		fetched += int64(len(records))
+		if int64(len(records)) < int64(pageSize) || fetched >= total {
+			break
+		}
+	}
+
+	statistics.Days = make([]DailySendStatistics, 0, len(days))
+	for _, day := range days {
+		statistics.Days = append(statistics.Days, *day)
+		statistics.Total.add(day.SendCounts)
+	}
+	sort.Slice(statistics.Days, func(i, j int) bool {
+		return statistics.Days[i].Date.Before(statistics.Days[j].Date)
+	})
+	return statusCode, resp, statistics, nil
+}
+
+// 解析接口返回的日期，例如 20240501，为空或格式不正确时返回零值
+func parseChinaDate(value string) time.Time {
+	t, err := time.ParseInLocation("20060102", strings.TrimSpace(value), chinaTimeZone)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
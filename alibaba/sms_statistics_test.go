@@ -0,0 +1,138 @@
+package alibaba
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// 模拟分页的发送统计，pages 中的每一项为一页记录
+func newStatisticsServer(t *testing.T, pages [][]map[string]interface{}, requests *[]url.Values) *Client {
+	t.Helper()
+	total := 0
+	for _, page := range pages {
+		total += len(page)
+	}
+	server := newApiServer(t, func(params url.Values) interface{} {
+		*requests = append(*requests, params)
+		index, _ := strconv.Atoi(params.Get("PageIndex"))
+		var records []map[string]interface{}
+		if index >= 1 && index <= len(pages) {
+			records = pages[index-1]
+		}
+		return map[string]interface{}{
+			"Code":      "OK",
+			"Message":   "OK",
+			"RequestId": "request-" + strconv.Itoa(index),
+			"Data":      map[string]interface{}{"TotalSize": total, "TargetList": records},
+		}
+	})
+	return newTestClient(t, server)
+}
+
+func statisticsRecord(date string, total, succeeded, failed, pending int) map[string]interface{} {
+	return map[string]interface{}{
+		"SendDate":              date,
+		"TotalCount":            total,
+		"RespondedSuccessCount": succeeded,
+		"RespondedFailCount":    failed,
+		"NoRespondedCount":      pending,
+	}
+}
+
+func TestQuerySendStatistics(t *testing.T) {
+	// 同一天的记录分布在不同的页中（例如按签名分别统计），日期乱序返回
+	pages := [][]map[string]interface{}{
+		{statisticsRecord("20240503", 10, 8, 1, 1), statisticsRecord("20240501", 5, 5, 0, 0)},
+		{statisticsRecord("20240502", 7, 6, 1, 0), statisticsRecord("20240503", 3, 2, 0, 1)},
+		{statisticsRecord("20240501", 2, 1, 1, 0)},
+	}
+	var requests []url.Values
+	client := newStatisticsServer(t, pages, &requests)
+
+	statusCode, resp, statistics, err := client.QuerySendStatistics(SendStatisticsQuery{
+		StartDate: time.Date(2024, 4, 30, 20, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 5, 3, 0, 0, 0, 0, chinaTimeZone),
+		SignName:  " 测试签名 ",
+		PageSize:  2,
+	})
+	if err != nil || statusCode != 200 || *resp.Code != "OK" {
+		t.Fatalf("QuerySendStatistics() = %d, %+v, %v", statusCode, resp, err)
+	}
+	if len(requests) != 3 {
+		t.Fatalf("requested %d pages, want 3", len(requests))
+	}
+	params := requests[0]
+	if params.Get("StartDate") != "20240501" || params.Get("EndDate") != "20240503" || params.Get("IsGlobe") != "1" ||
+		params.Get("SignName") != "测试签名" || params.Get("PageSize") != "2" || params.Get("PageIndex") != "1" {
+		t.Fatalf("request = %v", params)
+	}
+
+	want := []DailySendStatistics{
+		{Date: time.Date(2024, 5, 1, 0, 0, 0, 0, chinaTimeZone), SendCounts: SendCounts{Submitted: 7, Succeeded: 6, Failed: 1}},
+		{Date: time.Date(2024, 5, 2, 0, 0, 0, 0, chinaTimeZone), SendCounts: SendCounts{Submitted: 7, Succeeded: 6, Failed: 1}},
+		{Date: time.Date(2024, 5, 3, 0, 0, 0, 0, chinaTimeZone), SendCounts: SendCounts{Submitted: 13, Succeeded: 10, Failed: 1, Pending: 2}},
+	}
+	if len(statistics.Days) != len(want) {
+		t.Fatalf("Days = %+v", statistics.Days)
+	}
+	for i, day := range statistics.Days {
+		if !day.Date.Equal(want[i].Date) || day.SendCounts != want[i].SendCounts {
+			t.Errorf("Days[%d] = %+v, want %+v", i, day, want[i])
+		}
+	}
+	if wantTotal := (SendCounts{Submitted: 27, Succeeded: 22, Failed: 3, Pending: 2}); statistics.Total != wantTotal {
+		t.Fatalf("Total = %+v, want %+v", statistics.Total, wantTotal)
+	}
+}
+
+func TestQuerySendStatisticsScope(t *testing.T) {
+	tests := []struct {
+		scope   SendScope
+		isGlobe string
+	}{
+		{0, "1"},
+		{SendScopeDomestic, "1"},
+		{SendScopeGlobe, "2"},
+	}
+	for _, tt := range tests {
+		var requests []url.Values
+		client := newStatisticsServer(t, nil, &requests)
+		day := time.Date(2024, 5, 1, 0, 0, 0, 0, chinaTimeZone)
+		statusCode, _, statistics, err := client.QuerySendStatistics(SendStatisticsQuery{StartDate: day, EndDate: day, Scope: tt.scope})
+		if err != nil || statusCode != 200 || len(statistics.Days) != 0 {
+			t.Fatalf("scope %v: QuerySendStatistics() = %d, %+v, %v", tt.scope, statusCode, statistics, err)
+		}
+		if isGlobe := requests[0].Get("IsGlobe"); isGlobe != tt.isGlobe {
+			t.Errorf("scope %v: IsGlobe = %s, want %s", tt.scope, isGlobe, tt.isGlobe)
+		}
+		if pageSize := requests[0].Get("PageSize"); pageSize != strconv.Itoa(MaxSendStatisticsPageSize) {
+			t.Errorf("scope %v: PageSize = %s", tt.scope, pageSize)
+		}
+	}
+	if SendScopeGlobe.String() != "globe" || SendScope(3).String() != "SendScope(3)" {
+		t.Fatal("SendScope.String() mismatch")
+	}
+}
+
+func TestQuerySendStatisticsInvalidQuery(t *testing.T) {
+	var requests []url.Values
+	client := newStatisticsServer(t, nil, &requests)
+	day := time.Date(2024, 5, 2, 0, 0, 0, 0, chinaTimeZone)
+	invalid := []SendStatisticsQuery{
+		{EndDate: day},
+		{StartDate: day},
+		// 开始日期晚于结束日期（按北京时间取日期）
+		{StartDate: day, EndDate: day.Add(-time.Second)},
+		{StartDate: day, EndDate: day, Scope: 3},
+	}
+	for _, query := range invalid {
+		if statusCode, _, _, err := client.QuerySendStatistics(query); statusCode != 400 || err == nil {
+			t.Errorf("QuerySendStatistics(%+v) = %d, %v, want 400", query, statusCode, err)
+		}
+	}
+	if len(requests) != 0 {
+		t.Fatalf("%d invalid queries were sent", len(requests))
+	}
+}
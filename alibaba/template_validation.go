@@ -0,0 +1,208 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dysmsapi20170525 "github.com/alibabacloud-go/dysmsapi-20170525/v3/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// DefaultTemplateCacheTTL 模板内容的默认缓存时间
+const DefaultTemplateCacheTTL = 10 * time.Minute
+
+// 模板内容中的变量，例如 ${code}
+var templatePlaceholderPattern = regexp.MustCompile(`\$\{\s*([^}\s]+)\s*\}`)
+
+// TemplateParamError 模板参数与模板中的变量不一致
+type TemplateParamError struct {
+	// 短信模板 Code
+	TemplateCode string
+	// 接收对象的手机号码，批量发送时为参数不一致的号码，单发时为空
+	PhoneNumber string
+	// 模板中有但参数中缺少的变量
+	Missing []string
+	// 参数中有但模板中没有的变量
+	Unexpected []string
+}
+
+func (e *TemplateParamError) Error() string {
+	var b strings.Builder
+	b.WriteString("短信模板 ")
+	b.WriteString(e.TemplateCode)
+	b.WriteString(" 的模板参数不匹配")
+	if e.PhoneNumber != "" {
+		b.WriteString("（号码 ")
+		b.WriteString(e.PhoneNumber)
+		b.WriteString("）")
+	}
+	if len(e.Missing) > 0 {
+		b.WriteString("，缺少：")
+		b.WriteString(strings.Join(e.Missing, "、"))
+	}
+	if len(e.Unexpected) > 0 {
+		b.WriteString("，多余：")
+		b.WriteString(strings.Join(e.Unexpected, "、"))
+	}
+	return b.String()
+}
+
+// ExtractTemplatePlaceholders 提取模板内容中的变量名称（去重并排序），例如 "您的验证码为${code}" 返回 [code]
+func ExtractTemplatePlaceholders(content string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range templatePlaceholderPattern.FindAllStringSubmatch(content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 比较模板变量与参数，返回缺少和多余的变量
+func diffTemplateParams(placeholders []string, params map[string]string) (missing, unexpected []string) {
+	expected := make(map[string]bool, len(placeholders))
+	for _, name := range placeholders {
+		expected[name] = true
+		if _, ok := params[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	for name := range params {
+		if !expected[name] {
+			unexpected = append(unexpected, name)
+		}
+	}
+	sort.Strings(unexpected)
+	return missing, unexpected
+}
+
+type templateCacheEntry struct {
+	placeholders []string
+	expires      time.Time
+}
+
+// templateCache 模板变量缓存，键为模板 Code
+type templateCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]templateCacheEntry
+}
+
+func newTemplateCache(ttl time.Duration) *templateCache {
+	if ttl <= 0 {
+		ttl = DefaultTemplateCacheTTL
+	}
+	return &templateCache{ttl: ttl, entries: make(map[string]templateCacheEntry)}
+}
+
+func (t *templateCache) get(templateCode string) ([]string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[templateCode]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.placeholders, true
+}
+
+func (t *templateCache) put(templateCode string, placeholders []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries[templateCode] = templateCacheEntry{placeholders: placeholders, expires: time.Now().Add(t.ttl)}
+}
+
+func (t *templateCache) invalidate(templateCode string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, templateCode)
+}
+
+// 获取模板中的变量，优先使用缓存
+func (c *Client) templatePlaceholders(ctx context.Context, templateCode string) ([]string, error) {
+	if placeholders, ok := c.templateCache.get(templateCode); ok {
+		return placeholders, nil
+	}
+	result, err := invoke(ctx, c.runtimeOptions(nil), func(runtime *util.RuntimeOptions) (*dysmsapi20170525.QuerySmsTemplateResponse, error) {
+		return c.smsClient().QuerySmsTemplateWithOptions(&dysmsapi20170525.QuerySmsTemplateRequest{TemplateCode: tea.String(templateCode)}, runtime)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询短信模板 %s 的内容失败：%w", templateCode, err)
+	}
+	if code := tea.StringValue(result.Body.Code); code != "OK" {
+		return nil, fmt.Errorf("查询短信模板 %s 的内容失败：%s %s", templateCode, code, tea.StringValue(result.Body.Message))
+	}
+	placeholders := ExtractTemplatePlaceholders(tea.StringValue(result.Body.TemplateContent))
+	c.templateCache.put(templateCode, placeholders)
+	return placeholders, nil
+}
+
+// ValidateTemplateParams
+/** 校验模板参数是否与模板中的变量完全一致，模板内容通过 QuerySmsTemplate 查询并缓存
+ * @param templateCode 短信模板 Code
+ * @param params 模板参数
+ * @return error 参数不一致时返回 *TemplateParamError，查询模板失败时返回对应错误
+ */
+func (c *Client) ValidateTemplateParams(ctx context.Context, templateCode string, params map[string]string) error {
+	placeholders, err := c.templatePlaceholders(ctx, templateCode)
+	if err != nil {
+		return err
+	}
+	if missing, unexpected := diffTemplateParams(placeholders, params); len(missing) > 0 || len(unexpected) > 0 {
+		return &TemplateParamError{TemplateCode: templateCode, Missing: missing, Unexpected: unexpected}
+	}
+	return nil
+}
+
+// InvalidateTemplate 清除模板内容的缓存，修改或删除模板后调用（ModifySmsTemplate、DeleteSmsTemplate 会自动清除）
+func (c *Client) InvalidateTemplate(templateCode string) {
+	c.templateCache.invalidate(templateCode)
+}
+
+// 开启模板参数校验时校验请求，返回校验失败时的响应编码：参数不一致为 400，查询模板失败为 500
+func (c *Client) checkTemplateParams(ctx context.Context, req *resolvedSendRequest) (int32, error) {
+	if !c.validateParams {
+		return 200, nil
+	}
+	if err := c.validateSendRequest(ctx, req); err != nil {
+		var paramErr *TemplateParamError
+		if errors.As(err, &paramErr) {
+			return 400, err
+		}
+		return 500, err
+	}
+	return 200, nil
+}
+
+// 校验请求中每个号码的模板参数
+func (c *Client) validateSendRequest(ctx context.Context, req *resolvedSendRequest) error {
+	placeholders, err := c.templatePlaceholders(ctx, req.TemplateCode)
+	if err != nil {
+		return err
+	}
+	if req.Mode == SendModeSingle {
+		if missing, unexpected := diffTemplateParams(placeholders, req.TemplateParams); len(missing) > 0 || len(unexpected) > 0 {
+			return &TemplateParamError{TemplateCode: req.TemplateCode, Missing: missing, Unexpected: unexpected}
+		}
+		return nil
+	}
+	for _, recipient := range req.Recipients {
+		params := recipient.TemplateParams
+		if params == nil {
+			params = req.TemplateParams
+		}
+		if missing, unexpected := diffTemplateParams(placeholders, params); len(missing) > 0 || len(unexpected) > 0 {
+			return &TemplateParamError{TemplateCode: req.TemplateCode, PhoneNumber: recipient.PhoneNumber, Missing: missing, Unexpected: unexpected}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package alibaba
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExtractTemplatePlaceholders(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"您的验证码为${code}，5分钟内有效", []string{"code"}},
+		{"${name}您好，订单${order}已发货，${ name }请查收", []string{"name", "order"}},
+		{"没有变量的模板", nil},
+		{"${}不是变量，$code 也不是", nil},
+	}
+	for _, tt := range tests {
+		if got := ExtractTemplatePlaceholders(tt.content); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExtractTemplatePlaceholders(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+// 模拟模板查询与发送接口：没有号码参数的请求视为 QuerySmsTemplate
+func newTemplateServer(t *testing.T, queries *atomic.Int64, queryCode string, sent *atomic.Int64) *Client {
+	t.Helper()
+	server := newSmsServer(t, func(params url.Values) map[string]string {
+		if params.Get("PhoneNumbers") == "" && params.Get("PhoneNumberJson") == "" {
+			queries.Add(1)
+			if queryCode != "OK" {
+				return map[string]string{"Code": queryCode, "Message": "模板不存在", "RequestId": "request"}
+			}
+			return map[string]string{"Code": "OK", "Message": "OK", "RequestId": "request",
+				"TemplateCode": params.Get("TemplateCode"), "TemplateContent": "${name}您好，您的验证码为${code}"}
+		}
+		sent.Add(1)
+		return okResponse("biz")
+	})
+	return newTestClient(t, server, WithTemplateValidation(time.Hour))
+}
+
+func TestValidateTemplateParams(t *testing.T) {
+	var queries, sent atomic.Int64
+	client := newTemplateServer(t, &queries, "OK", &sent)
+	ctx := context.Background()
+
+	if err := client.ValidateTemplateParams(ctx, "SMS_1", map[string]string{"name": "张三", "code": "1234"}); err != nil {
+		t.Fatal(err)
+	}
+	err := client.ValidateTemplateParams(ctx, "SMS_1", map[string]string{"code": "1234", "extra": "x", "another": "y"})
+	var paramErr *TemplateParamError
+	if !errors.As(err, &paramErr) {
+		t.Fatalf("ValidateTemplateParams() error = %v, want *TemplateParamError", err)
+	}
+	if !reflect.DeepEqual(paramErr.Missing, []string{"name"}) || !reflect.DeepEqual(paramErr.Unexpected, []string{"another", "extra"}) {
+		t.Fatalf("TemplateParamError = %+v", paramErr)
+	}
+
+	// 模板内容已缓存，清除缓存后重新查询
+	if queries.Load() != 1 {
+		t.Fatalf("QuerySmsTemplate calls = %d, want 1", queries.Load())
+	}
+	client.InvalidateTemplate("SMS_1")
+	if err := client.ValidateTemplateParams(ctx, "SMS_1", map[string]string{"name": "张三", "code": "1234"}); err != nil {
+		t.Fatal(err)
+	}
+	if queries.Load() != 2 {
+		t.Fatalf("QuerySmsTemplate calls = %d, want 2", queries.Load())
+	}
+}
+
+func TestTemplateCacheExpiry(t *testing.T) {
+	cache := newTemplateCache(20 * time.Millisecond)
+	cache.put("SMS_1", []string{"code"})
+	if placeholders, ok := cache.get("SMS_1"); !ok || !reflect.DeepEqual(placeholders, []string{"code"}) {
+		t.Fatalf("get() = %v, %v", placeholders, ok)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := cache.get("SMS_1"); ok {
+		t.Fatal("expired entry was returned")
+	}
+	if newTemplateCache(0).ttl != DefaultTemplateCacheTTL {
+		t.Fatal("zero TTL did not use DefaultTemplateCacheTTL")
+	}
+}
+
+func TestSendChecksTemplateParams(t *testing.T) {
+	var queries, sent atomic.Int64
+	client := newTemplateServer(t, &queries, "OK", &sent)
+
+	// 参数不一致时不发送，响应编码为 400
+	statusCode, _, err := client.Send(SendRequest{Recipients: Recipients("13800000000"), TemplateCode: "SMS_1", TemplateParams: map[string]string{"code": "1234"}})
+	var paramErr *TemplateParamError
+	if statusCode != 400 || !errors.As(err, &paramErr) || sent.Load() != 0 {
+		t.Fatalf("Send() = %d, %v, sent %d", statusCode, err, sent.Load())
+	}
+	statusCode, _, err = client.Send(SendRequest{Recipients: Recipients("13800000000"), TemplateCode: "SMS_1", TemplateParams: map[string]string{"name": "张三", "code": "1234"}})
+	if statusCode != 200 || err != nil || sent.Load() != 1 {
+		t.Fatalf("Send() = %d, %v, sent %d", statusCode, err, sent.Load())
+	}
+
+	// 查询模板失败时不发送，响应编码为 500
+	failing := newTemplateServer(t, &queries, "isv.SMS_TEMPLATE_ILLEGAL", &sent)
+	statusCode, _, err = failing.Send(SendRequest{Recipients: Recipients("13800000000"), TemplateCode: "SMS_2", TemplateParams: map[string]string{"name": "张三", "code": "1234"}})
+	if statusCode != 500 || err == nil || errors.As(err, &paramErr) || sent.Load() != 1 {
+		t.Fatalf("Send() = %d, %v, sent %d", statusCode, err, sent.Load())
+	}
+}
+
+func TestSendBatchChecksEachRecipient(t *testing.T) {
+	var queries, sent atomic.Int64
+	client := newTemplateServer(t, &queries, "OK", &sent)
+
+	recipients := []Recipient{
+		{PhoneNumber: "13800000000", TemplateParams: map[string]string{"name": "张三", "code": "1234"}},
+		// 没有单独参数的号码使用请求的公共参数
+		{PhoneNumber: "13800000001"},
+		{PhoneNumber: "13800000002", TemplateParams: map[string]string{"name": "王五"}},
+	}
+	req := SendRequest{Mode: SendModeBatch, Recipients: recipients, TemplateCode: "SMS_1", TemplateParams: map[string]string{"name": "李四", "code": "5678"}}
+	statusCode, _, err := client.SendBatch(req, 1)
+	var paramErr *TemplateParamError
+	if statusCode != 400 || !errors.As(err, &paramErr) || sent.Load() != 0 {
+		t.Fatalf("SendBatch() = %d, %v, sent %d", statusCode, err, sent.Load())
+	}
+	if paramErr.PhoneNumber != "13800000002" || !reflect.DeepEqual(paramErr.Missing, []string{"code"}) {
+		t.Fatalf("TemplateParamError = %+v", paramErr)
+	}
+
+	req.Recipients[2].TemplateParams["code"] = "9012"
+	statusCode, _, err = client.SendBatch(req, 1)
+	if statusCode != 200 || err != nil || sent.Load() != 1 {
+		t.Fatalf("SendBatch() = %d, %v, sent %d", statusCode, err, sent.Load())
+	}
+	if queries.Load() != 1 {
+		t.Fatalf("QuerySmsTemplate calls = %d, want 1", queries.Load())
+	}
+}
@@ -0,0 +1,160 @@
+// sms_credential 加密、解密短信客户端使用的凭证文件
+/**
+ * 用法：
+ *	sms_credential genkey
+ *	sms_credential encrypt [-key-file 密钥文件] [-age-recipient age 公钥]... [-in 明文文件] [-out 加密文件]
+ *	sms_credential decrypt [-key-file 密钥文件] [-age-identity age 身份文件] [-in 加密文件] [-out 明文文件]
+ * 未指定 -key-file 时使用 ALIBABA_CLOUD_CREDENTIALS_KEY、ALIBABA_CLOUD_CREDENTIALS_KEY_FILE 环境变量中的密钥；
+ * 指定 -age-recipient 时使用 age 格式加密。-in、-out 未指定时分别读取标准输入、写入标准输出
+ * 明文文件的格式与 ~/.alibabacloud/credentials 相同，例如：
+ *	[default]
+ *	type = access_key
+ *	access_key_id = xxx
+ *	access_key_secret = xxx
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"third_party_tool_library/alibaba"
+)
+
+const usage = `用法：
+  sms_credential genkey
+  sms_credential encrypt [-key-file 密钥文件] [-age-recipient age 公钥]... [-in 明文文件] [-out 加密文件]
+  sms_credential decrypt [-key-file 密钥文件] [-age-identity age 身份文件] [-in 加密文件] [-out 明文文件]
+`
+
+// 可重复指定的命令行参数
+type stringList []string
+
+func (l *stringList) String() string {
+	return fmt.Sprint(*l)
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "genkey":
+		err = genkey()
+	case "encrypt":
+		err = encrypt(os.Args[2:])
+	case "decrypt":
+		err = decrypt(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// 生成 AES-256 密钥
+func genkey() error {
+	key, err := alibaba.GenerateCredentialsKey()
+	if err != nil {
+		return err
+	}
+	fmt.Println(key)
+	return nil
+}
+
+// 加密凭证文件
+func encrypt(args []string) error {
+	flags := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	keyFile := flags.String("key-file", "", "AES-256 密钥文件")
+	in := flags.String("in", "", "明文凭证文件，默认读取标准输入")
+	out := flags.String("out", "", "加密后的凭证文件，默认写入标准输出")
+	var recipients stringList
+	flags.Var(&recipients, "age-recipient", "age 公钥，指定时使用 age 格式加密，可重复指定")
+	_ = flags.Parse(args)
+
+	plaintext, err := readInput(*in)
+	if err != nil {
+		return err
+	}
+	if alibaba.IsEncryptedCredentials(plaintext) {
+		return fmt.Errorf("输入的凭证文件已经加密")
+	}
+	var encrypted []byte
+	if len(recipients) > 0 {
+		encrypted, err = alibaba.EncryptCredentialsAge(plaintext, recipients...)
+	} else {
+		var key []byte
+		if key, err = aesKey(*keyFile); err != nil {
+			return err
+		}
+		encrypted, err = alibaba.EncryptCredentials(plaintext, key)
+	}
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, encrypted)
+}
+
+// 解密凭证文件
+func decrypt(args []string) error {
+	flags := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	keyFile := flags.String("key-file", "", "AES-256 密钥文件")
+	identityFile := flags.String("age-identity", "", "age 身份文件")
+	in := flags.String("in", "", "加密的凭证文件，默认读取标准输入")
+	out := flags.String("out", "", "解密后的凭证文件，默认写入标准输出")
+	_ = flags.Parse(args)
+
+	encrypted, err := readInput(*in)
+	if err != nil {
+		return err
+	}
+	if !alibaba.IsEncryptedCredentials(encrypted) {
+		return fmt.Errorf("输入的凭证文件没有加密")
+	}
+	plaintext, err := alibaba.DecryptCredentials(encrypted, alibaba.CredentialsKey{KeyFile: *keyFile, AgeIdentityFile: *identityFile})
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, plaintext)
+}
+
+// 读取 AES 密钥：优先使用 -key-file，其次使用环境变量
+func aesKey(keyFile string) ([]byte, error) {
+	if keyFile == "" {
+		keyFile = os.Getenv(alibaba.EnvCredentialsKeyFile)
+	}
+	if keyFile != "" {
+		return os.ReadFile(keyFile)
+	}
+	if key := os.Getenv(alibaba.EnvCredentialsKey); key != "" {
+		return []byte(key), nil
+	}
+	return nil, fmt.Errorf("请使用 -key-file 或 %s 环境变量指定密钥，也可以使用 -age-recipient 进行 age 加密", alibaba.EnvCredentialsKey)
+}
+
+func readInput(filename string) ([]byte, error) {
+	if filename == "" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(filename)
+}
+
+func writeOutput(filename string, data []byte) error {
+	if filename == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(filename, data, 0600)
+}
@@ -3,8 +3,26 @@ package third_party_tool_library
 type ResponseResult struct {
 	Code    *string
 	Message *string
+	// 请求 ID，向阿里云提交工单排查问题时需要提供
+	RequestId *string
+	// 发送回执 ID，仅发送接口返回，用于查询发送状态（QuerySendDetails）
+	BizId *string
+	// 请求次数（包含重试），仅发送接口返回，按幂等键直接返回之前的结果时为 0
+	Attempts int
+	// 号码不合法、未发送的接收对象，仅批量发送时返回
+	Rejected []RejectedRecipient
+}
+
+// RejectedRecipient 号码不合法、未发送的接收对象
+type RejectedRecipient struct {
+	// 在请求中的下标，从 0 开始
+	Index int
+	// 原始输入的手机号码
+	PhoneNumber string
+	// 不合法的原因
+	Reason string
 }
 
 func NewResult(code *string, message *string) ResponseResult {
-	return ResponseResult{code, message}
+	return ResponseResult{Code: code, Message: message}
 }